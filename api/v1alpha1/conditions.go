@@ -0,0 +1,16 @@
+package v1alpha1
+
+const (
+	// ConditionTypeReady is set to true when all ClusterRoles / Roles or
+	// ClusterRoleBindings / RoleBindings were created by the operator.
+	ConditionTypeReady = "Ready"
+	// ConditionTypeDegraded is set to true when the last reconciliation of a
+	// NamespaceRole or NamespaceRoleBinding failed.
+	ConditionTypeDegraded = "Degraded"
+	// ConditionTypeProgressing is set to true when the operator is waiting for
+	// other resources, e.g. namespaces which do not exist yet.
+	ConditionTypeProgressing = "Progressing"
+	// ConditionTypeNamespacesMissing is set to true when at least one namespace
+	// from the Namespaces list of a NamespaceRole doesn't exist.
+	ConditionTypeNamespacesMissing = "NamespacesMissing"
+)
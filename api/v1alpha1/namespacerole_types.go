@@ -7,11 +7,30 @@ import (
 
 // NamespaceRoleSpec defines the desired state of NamespaceRole
 type NamespaceRoleSpec struct {
-	// Namespaces is a list of namespace the Roles should be created in. If the
-	// list only contains one value, which is equal to "*", a ClusterRole instead
-	// of a Role will be created.
-	Namespaces []string            `json:"namespaces"`
-	Rules      []rbacv1.PolicyRule `json:"rules"`
+	// Namespaces is a list of namespace the Roles should be created in. An
+	// entry can also be a glob pattern (e.g. "team-a-*") or a regular expression
+	// prefixed with "re:" (e.g. "re:^preview-[0-9]+$"), which is matched against
+	// all existing namespaces. If the list only contains one value, which is
+	// equal to "*", and no exclusions are defined, a ClusterRole instead of a
+	// Role will be created.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the Roles should be created in by
+	// their labels. The selected namespaces are added to the namespaces from the
+	// Namespaces list.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ExcludeNamespaces is a list of namespaces, which are removed from the
+	// namespaces selected via the Namespaces list and the NamespaceSelector.
+	// Like in the Namespaces list an entry can be a glob pattern or a regular
+	// expression.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	// ExcludeNamespaceSelector removes all namespaces matching the selector from
+	// the namespaces selected via the Namespaces list and the NamespaceSelector.
+	// +optional
+	ExcludeNamespaceSelector *metav1.LabelSelector `json:"excludeNamespaceSelector,omitempty"`
+	Rules                    []rbacv1.PolicyRule   `json:"rules"`
 }
 
 // NamespaceRoleStatus defines the observed state of NamespaceRole
@@ -20,8 +39,25 @@ type NamespaceRoleStatus struct {
 	Selector string `json:"selector,omitempty"`
 	// ClusterRoles is a list of ClusterRoles which were created by the operator.
 	ClusterRoles []NamespaceRoleStatusRole `json:"clusterRoles,omitempty"`
+	// ObservedGeneration is the generation of the NamespaceRole, which was
+	// reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Roles is a list of Roles which were created by the operator.
 	Roles []NamespaceRoleStatusRole `json:"roles,omitempty"`
+	// MissingNamespaces is a list of namespaces from the Namespaces list, which
+	// do not exist yet. The Roles for these namespaces are created as soon as
+	// the namespaces are created.
+	MissingNamespaces []string `json:"missingNamespaces,omitempty"`
+	// Failures is a list of objects, which couldn't be created, updated or
+	// deleted in the last reconciliation. The operator retries these objects
+	// with a backoff, while all other objects are still reconciled.
+	Failures []NamespaceRoleStatusFailure `json:"failures,omitempty"`
+	// Conditions describe the current state of the NamespaceRole.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type NamespaceRoleStatusRole struct {
@@ -29,13 +65,29 @@ type NamespaceRoleStatusRole struct {
 	Namespace string `json:"namespace"`
 }
 
+// NamespaceRoleStatusFailure describes an object, which couldn't be created,
+// updated or deleted by the operator.
+type NamespaceRoleStatusFailure struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Message   string `json:"message"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:deprecatedversion:warning="kobs.io/v1alpha1 NamespaceRole is deprecated and will be removed in a future release; use kobs.io/v1alpha2 NamespaceRole"
 
-// NamespaceRole is the Schema for the namespaceroles API
+// NamespaceRole is the Schema for the namespaceroles API. The v1alpha1 API is
+// deprecated, because it can not represent the fields of the v1alpha2 API and
+// drops them on every update. It is still served, so that existing manifests
+// can be applied, and will be removed in a future release.
 // +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`,description="List of namespaces for which the NamespaceRole is used"
 // +kubebuilder:printcolumn:name="Selector",type=string,JSONPath=`.status.selector`,description="Selector to get all ClusterRoles / Roles created by the operator"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Indicates if all ClusterRoles / Roles were created"
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`,description="Indicates if the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`,description="Indicates if the operator is waiting for namespaces",priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Time when this NamespaceRole was created"
 type NamespaceRole struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -30,6 +30,19 @@ type NamespaceRoleBindingStatus struct {
 	ClusterRoleBindings []NamespaceRoleStatusRoleBinding `json:"clusterRoleBindings,omitempty"`
 	// RoleBinding is a list of RoleBindings which were created by the operator.
 	RoleBindings []NamespaceRoleStatusRoleBinding `json:"roleBindings,omitempty"`
+	// Failures is a list of objects, which couldn't be created, updated or
+	// deleted in the last reconciliation. The operator retries these objects
+	// with a backoff, while all other objects are still reconciled.
+	Failures []NamespaceRoleStatusFailure `json:"failures,omitempty"`
+	// ObservedGeneration is the generation of the NamespaceRoleBinding, which
+	// was reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the NamespaceRoleBinding.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type NamespaceRoleStatusRoleBinding struct {
@@ -40,10 +53,17 @@ type NamespaceRoleStatusRoleBinding struct {
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:deprecatedversion:warning="kobs.io/v1alpha1 NamespaceRoleBinding is deprecated and will be removed in a future release; use kobs.io/v1alpha2 NamespaceRoleBinding"
 
-// NamespaceRoleBinding is the Schema for the namespacerolebindings API
+// NamespaceRoleBinding is the Schema for the namespacerolebindings API. The
+// v1alpha1 API is deprecated, because it can not represent the fields of the
+// v1alpha2 API and drops them on every update. It is still served, so that
+// existing manifests can be applied, and will be removed in a future release.
 // +kubebuilder:printcolumn:name="NamespaceRole",type=string,JSONPath=`.spec.roleRef.name`,description="The NamespaceRole used by the NamespaceRoleBinding"
 // +kubebuilder:printcolumn:name="Selector",type=string,JSONPath=`.status.selector`,description="Selector to get all ClusterRoleBindings / RoleBindings created by the operator"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Indicates if all ClusterRoleBindings / RoleBindings were created"
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`,description="Indicates if the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`,description="Indicates if the operator is waiting for other resources",priority=1
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Time when this NamespaceRoleBinding was created"
 type NamespaceRoleBinding struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -5,7 +5,8 @@
 package v1alpha1
 
 import (
-	"k8s.io/api/rbac/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -101,7 +102,7 @@ func (in *NamespaceRoleBindingSpec) DeepCopyInto(out *NamespaceRoleBindingSpec)
 	out.RoleRef = in.RoleRef
 	if in.Subjects != nil {
 		in, out := &in.Subjects, &out.Subjects
-		*out = make([]v1.Subject, len(*in))
+		*out = make([]rbacv1.Subject, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -144,6 +145,18 @@ func (in *NamespaceRoleBindingStatus) DeepCopyInto(out *NamespaceRoleBindingStat
 		*out = make([]NamespaceRoleStatusRoleBinding, len(*in))
 		copy(*out, *in)
 	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]NamespaceRoleStatusFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceRoleBindingStatus.
@@ -196,9 +209,24 @@ func (in *NamespaceRoleSpec) DeepCopyInto(out *NamespaceRoleSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaceSelector != nil {
+		in, out := &in.ExcludeNamespaceSelector, &out.ExcludeNamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Rules != nil {
 		in, out := &in.Rules, &out.Rules
-		*out = make([]v1.PolicyRule, len(*in))
+		*out = make([]rbacv1.PolicyRule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -228,6 +256,23 @@ func (in *NamespaceRoleStatus) DeepCopyInto(out *NamespaceRoleStatus) {
 		*out = make([]NamespaceRoleStatusRole, len(*in))
 		copy(*out, *in)
 	}
+	if in.MissingNamespaces != nil {
+		in, out := &in.MissingNamespaces, &out.MissingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]NamespaceRoleStatusFailure, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceRoleStatus.
@@ -240,6 +285,21 @@ func (in *NamespaceRoleStatus) DeepCopy() *NamespaceRoleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceRoleStatusFailure) DeepCopyInto(out *NamespaceRoleStatusFailure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceRoleStatusFailure.
+func (in *NamespaceRoleStatusFailure) DeepCopy() *NamespaceRoleStatusFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceRoleStatusFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceRoleStatusRole) DeepCopyInto(out *NamespaceRoleStatusRole) {
 	*out = *in
@@ -0,0 +1,176 @@
+package v1alpha2
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessRequestPhase is the phase of an AccessRequest.
+// +kubebuilder:validation:Enum=Pending;Approved;Denied;Expired
+type AccessRequestPhase string
+
+const (
+	// AccessRequestPhasePending is the phase of an AccessRequest, which doesn't
+	// have enough approvals yet.
+	AccessRequestPhasePending AccessRequestPhase = "Pending"
+	// AccessRequestPhaseApproved is the phase of an AccessRequest, which was
+	// approved. The access is granted via a NamespaceRoleBinding until the
+	// requested duration is over.
+	AccessRequestPhaseApproved AccessRequestPhase = "Approved"
+	// AccessRequestPhaseDenied is the phase of an AccessRequest, which was
+	// denied by an approver.
+	AccessRequestPhaseDenied AccessRequestPhase = "Denied"
+	// AccessRequestPhaseExpired is the phase of an approved AccessRequest,
+	// after the requested duration is over.
+	AccessRequestPhaseExpired AccessRequestPhase = "Expired"
+)
+
+// AccessRequestDecision is the decision of an approver.
+// +kubebuilder:validation:Enum=Approved;Denied
+type AccessRequestDecision string
+
+const (
+	// AccessRequestDecisionApproved approves an AccessRequest.
+	AccessRequestDecisionApproved AccessRequestDecision = "Approved"
+	// AccessRequestDecisionDenied denies an AccessRequest.
+	AccessRequestDecisionDenied AccessRequestDecision = "Denied"
+)
+
+// AccessRequestSpec defines the desired state of AccessRequest. Except for the
+// Approvals field, the spec can not be changed after the AccessRequest was
+// created. The rules of an optional field are only evaluated when the field is
+// set in the old and the new object, so that the spec also checks that optional
+// fields are not added or removed.
+// +kubebuilder:validation:XValidation:rule="duration(self.duration) > duration('0s')",message="duration must be positive"
+// +kubebuilder:validation:XValidation:rule="has(self.namespaces) == has(oldSelf.namespaces)",message="namespaces is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.reason) == has(oldSelf.reason)",message="reason is immutable"
+// +kubebuilder:validation:XValidation:rule="has(self.requestedBy) == has(oldSelf.requestedBy)",message="requestedBy is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(oldSelf.approvals) || has(self.approvals)",message="approvals can not be removed"
+type AccessRequestSpec struct {
+	// RoleRef is a reference to the NamespaceRole, which is requested.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="roleRef is immutable"
+	RoleRef NamespaceRoleBindingSpecRoleRef `json:"roleRef"`
+	// Namespaces is a list of namespaces the NamespaceRole is requested for. If
+	// it is not set, the access is requested for all namespaces of the
+	// NamespaceRole.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="namespaces is immutable"
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Subjects is the list of subjects, which should get the requested access.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="subjects is immutable"
+	Subjects []rbacv1.Subject `json:"subjects"`
+	// Duration is the duration the access is granted for, after the
+	// AccessRequest was approved.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="duration is immutable"
+	Duration metav1.Duration `json:"duration"`
+	// Reason is the reason for the AccessRequest, which is shown to the
+	// approvers.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="reason is immutable"
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// RequiredApprovals is the number of approvals, which are required to grant
+	// the access.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="requiredApprovals is immutable"
+	// +optional
+	RequiredApprovals int `json:"requiredApprovals,omitempty"`
+	// RequestedBy is the name of the user, who created the AccessRequest. It is
+	// set by the admission webhook of the operator from the authenticated
+	// request and can not be set to another user by the client.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="requestedBy is immutable"
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+	// Approvals is the list of decisions of the approvers. Approvers add their
+	// decision to this list via an update of the AccessRequest. Existing
+	// decisions can not be changed or removed. The User and Time fields of a
+	// new decision are set by the admission webhook of the operator from the
+	// authenticated request, which also rejects decisions of users, who are not
+	// allowed to "approve" the AccessRequest according to a
+	// SubjectAccessReview, of the creator and of the subjects of the
+	// AccessRequest.
+	// +optional
+	Approvals []AccessRequestApproval `json:"approvals,omitempty"`
+}
+
+// AccessRequestApproval is the decision of an approver for an AccessRequest.
+type AccessRequestApproval struct {
+	// User is the name of the approver. It is set by the admission webhook of
+	// the operator from the authenticated request.
+	// +optional
+	User     string                `json:"user,omitempty"`
+	Decision AccessRequestDecision `json:"decision"`
+	// +optional
+	Comment string `json:"comment,omitempty"`
+	// Time is the time of the decision. It is set by the admission webhook of
+	// the operator.
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// AccessRequestStatus defines the observed state of AccessRequest. The status
+// is only written by the operator and always derived from the spec, so that
+// write access to the accessrequests/status subresource is only required by the
+// operator.
+type AccessRequestStatus struct {
+	// Phase is the current phase of the AccessRequest.
+	// +optional
+	Phase AccessRequestPhase `json:"phase,omitempty"`
+	// ApprovedBy is the list of approvers, whose decisions were accepted by the
+	// operator.
+	// +optional
+	ApprovedBy []string `json:"approvedBy,omitempty"`
+	// ApprovedAt is the time of the approval, with which the AccessRequest got
+	// the required number of approvals.
+	// +optional
+	ApprovedAt *metav1.Time `json:"approvedAt,omitempty"`
+	// ExpiresAt is the time when the granted access is revoked. It is always
+	// ApprovedAt plus the requested duration.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// NamespaceRoleBinding is the name of the NamespaceRoleBinding, which was
+	// created for the AccessRequest.
+	// +optional
+	NamespaceRoleBinding string `json:"namespaceRoleBinding,omitempty"`
+	// ObservedGeneration is the generation of the AccessRequest, which was
+	// reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the AccessRequest.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// AccessRequest is the Schema for the accessrequests API
+// +kubebuilder:printcolumn:name="NamespaceRole",type=string,JSONPath=`.spec.roleRef.name`,description="The requested NamespaceRole"
+// +kubebuilder:printcolumn:name="Duration",type=string,JSONPath=`.spec.duration`,description="The requested duration"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The phase of the AccessRequest"
+// +kubebuilder:printcolumn:name="Expires",type=string,JSONPath=`.status.expiresAt`,description="Time when the granted access is revoked",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Time when this AccessRequest was created"
+type AccessRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AccessRequestSpec   `json:"spec,omitempty"`
+	Status AccessRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AccessRequestList contains a list of AccessRequest
+type AccessRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AccessRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AccessRequest{}, &AccessRequestList{})
+}
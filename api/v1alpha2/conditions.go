@@ -0,0 +1,24 @@
+package v1alpha2
+
+const (
+	// ConditionTypeReady is set to true when all ClusterRoles / Roles or
+	// ClusterRoleBindings / RoleBindings were created by the operator.
+	ConditionTypeReady = "Ready"
+	// ConditionTypeDegraded is set to true when the last reconciliation of a
+	// NamespaceRole or NamespaceRoleBinding failed.
+	ConditionTypeDegraded = "Degraded"
+	// ConditionTypeProgressing is set to true when the operator is waiting for
+	// other resources, e.g. namespaces which do not exist yet.
+	ConditionTypeProgressing = "Progressing"
+	// ConditionTypeNamespacesMissing is set to true when at least one namespace
+	// from the Namespaces list of a NamespaceRole doesn't exist.
+	ConditionTypeNamespacesMissing = "NamespacesMissing"
+	// ConditionTypeActive is set to true when a NamespaceRoleBinding is inside
+	// of its validity window, so that the ClusterRoleBindings / RoleBindings
+	// are created.
+	ConditionTypeActive = "Active"
+	// ConditionTypeBreakGlass is set to true when a NamespaceRoleBinding granted
+	// emergency access, until the access was acknowledged via the
+	// "kobs.io/break-glass-acknowledged" annotation.
+	ConditionTypeBreakGlass = "BreakGlass"
+)
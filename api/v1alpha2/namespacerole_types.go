@@ -0,0 +1,246 @@
+package v1alpha2
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceRoleScope defines which kind of roles are created for a
+// NamespaceRole.
+// +kubebuilder:validation:Enum=Cluster;Namespaced;Both
+type NamespaceRoleScope string
+
+const (
+	// NamespaceRoleScopeCluster creates a ClusterRole with all rules.
+	NamespaceRoleScopeCluster NamespaceRoleScope = "Cluster"
+	// NamespaceRoleScopeNamespaced creates a Role in each selected namespace.
+	// Rules for cluster-scoped resources and non-resource URLs are added to a
+	// companion ClusterRole, where the rules for namespaces are narrowed down to
+	// the selected namespaces.
+	NamespaceRoleScopeNamespaced NamespaceRoleScope = "Namespaced"
+	// NamespaceRoleScopeBoth creates a ClusterRole with all rules for
+	// cluster-scoped resources and non-resource URLs and a Role with all rules
+	// for namespaced resources in each selected namespace.
+	NamespaceRoleScopeBoth NamespaceRoleScope = "Both"
+)
+
+// NamespaceRoleIncludeKind is the kind of an object, whose rules are included
+// in a NamespaceRole.
+// +kubebuilder:validation:Enum=NamespaceRole;ClusterRole
+type NamespaceRoleIncludeKind string
+
+const (
+	// NamespaceRoleIncludeKindNamespaceRole includes the rules of another
+	// NamespaceRole, including the rules of all objects it includes.
+	NamespaceRoleIncludeKindNamespaceRole NamespaceRoleIncludeKind = "NamespaceRole"
+	// NamespaceRoleIncludeKindClusterRole includes the rules of a ClusterRole.
+	NamespaceRoleIncludeKindClusterRole NamespaceRoleIncludeKind = "ClusterRole"
+)
+
+// NamespaceRoleInclude references a NamespaceRole or ClusterRole, whose rules
+// are merged into the rules of a NamespaceRole.
+type NamespaceRoleInclude struct {
+	Kind NamespaceRoleIncludeKind `json:"kind"`
+	Name string                   `json:"name"`
+}
+
+// NamespaceRoleOverrideMode defines how the rules of an override are applied to
+// the Roles.
+// +kubebuilder:validation:Enum=Append;Replace
+type NamespaceRoleOverrideMode string
+
+const (
+	// NamespaceRoleOverrideModeAppend adds the rules of the override to the
+	// rules of the Role.
+	NamespaceRoleOverrideModeAppend NamespaceRoleOverrideMode = "Append"
+	// NamespaceRoleOverrideModeReplace replaces the rules of the Role with the
+	// rules of the override.
+	NamespaceRoleOverrideModeReplace NamespaceRoleOverrideMode = "Replace"
+)
+
+// NamespaceRoleOverride defines additional or replacement rules for the Roles
+// in the selected namespaces.
+// +kubebuilder:validation:XValidation:rule="has(self.namespaces) || has(self.namespaceSelector)",message="namespaces or namespaceSelector is required"
+type NamespaceRoleOverride struct {
+	// Namespaces is a list of namespaces the override is applied to. Like in
+	// the Namespaces list of the NamespaceRole an entry can be a glob pattern or
+	// a regular expression.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the override is applied to by
+	// their labels.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Mode defines if the rules are appended to the rules of the Role or if
+	// they replace the rules of the Role.
+	// +kubebuilder:default=Append
+	// +optional
+	Mode  NamespaceRoleOverrideMode `json:"mode,omitempty"`
+	Rules []rbacv1.PolicyRule       `json:"rules"`
+}
+
+// NamespaceRoleSpec defines the desired state of NamespaceRole
+// +kubebuilder:validation:XValidation:rule="(has(self.scope) && self.scope == 'Cluster') || has(self.namespaces) || has(self.namespaceSelector)",message="namespaces or namespaceSelector is required, when the scope is not Cluster"
+// +kubebuilder:validation:XValidation:rule="!(has(self.clusterRoleRef) && has(self.rules))",message="rules and clusterRoleRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.clusterRoleRef) && has(self.scope) && self.scope == 'Both')",message="clusterRoleRef can not be used with the scope Both"
+// +kubebuilder:validation:XValidation:rule="!(has(self.clusterRoleRef) && has(self.includes))",message="includes and clusterRoleRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.clusterRoleRef) && has(self.ruleSelector))",message="ruleSelector and clusterRoleRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!(has(self.clusterRoleRef) && has(self.overrides))",message="overrides and clusterRoleRef are mutually exclusive"
+type NamespaceRoleSpec struct {
+	// Scope defines if a ClusterRole, Roles or both are created for the
+	// NamespaceRole.
+	// +kubebuilder:validation:Required
+	Scope NamespaceRoleScope `json:"scope"`
+	// Namespaces is a list of namespace the Roles should be created in. An
+	// entry can also be a glob pattern (e.g. "team-a-*") or a regular expression
+	// prefixed with "re:" (e.g. "re:^preview-[0-9]+$"), which is matched against
+	// all existing namespaces. The namespaces are ignored when the scope is
+	// Cluster.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the Roles should be created in by
+	// their labels. The selected namespaces are added to the namespaces from the
+	// Namespaces list.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// ExcludeNamespaces is a list of namespaces, which are removed from the
+	// namespaces selected via the Namespaces list and the NamespaceSelector.
+	// Like in the Namespaces list an entry can be a glob pattern or a regular
+	// expression.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+	// ExcludeNamespaceSelector removes all namespaces matching the selector from
+	// the namespaces selected via the Namespaces list and the NamespaceSelector.
+	// +optional
+	ExcludeNamespaceSelector *metav1.LabelSelector `json:"excludeNamespaceSelector,omitempty"`
+	// Rules is the list of rules, which are added to the generated ClusterRoles /
+	// Roles. The API groups, resources and resource names of the rules for the
+	// Roles can contain the variables "${namespace}" and "${label:<key>}", which
+	// are replaced with the name and the label values of the namespace.
+	// +optional
+	Rules []rbacv1.PolicyRule `json:"rules,omitempty"`
+	// Includes is a list of NamespaceRoles and ClusterRoles, whose rules are
+	// merged into the Rules. Changes to an included object are applied to all
+	// NamespaceRoles including it.
+	// +optional
+	Includes []NamespaceRoleInclude `json:"includes,omitempty"`
+	// RuleSelector selects ClusterRoles by their labels, whose rules are merged
+	// into the Rules, like the aggregationRule of a ClusterRole. The generated
+	// ClusterRoles / Roles are updated when a matching ClusterRole is created,
+	// changed or deleted. ClusterRoles created by the operator are never
+	// selected.
+	// +optional
+	RuleSelector *metav1.LabelSelector `json:"ruleSelector,omitempty"`
+	// Overrides is a list of additional or replacement rules for the Roles in
+	// some of the namespaces, e.g. to allow the deletion of pods only in
+	// staging namespaces. The overrides are applied in the order of the list
+	// and only affect the Roles, not the ClusterRoles.
+	// +optional
+	Overrides []NamespaceRoleOverride `json:"overrides,omitempty"`
+	// ClusterRoleRef is the name of an existing ClusterRole (e.g. "edit" or
+	// "view"), which is used instead of the Rules. If it is set, no ClusterRoles /
+	// Roles are generated. Instead the NamespaceRoleBindings bind the referenced
+	// ClusterRole via a ClusterRoleBinding for the scope Cluster or via
+	// RoleBindings in all selected namespaces otherwise.
+	// +optional
+	ClusterRoleRef string `json:"clusterRoleRef,omitempty"`
+}
+
+// NamespaceRoleStatus defines the observed state of NamespaceRole
+type NamespaceRoleStatus struct {
+	// The label selector to get all ClusterRoles / Roles created by the operator.
+	Selector string `json:"selector,omitempty"`
+	// ClusterRoles is a list of ClusterRoles which were created by the operator.
+	ClusterRoles []NamespaceRoleStatusRole `json:"clusterRoles,omitempty"`
+	// ObservedGeneration is the generation of the NamespaceRole, which was
+	// reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Roles is a list of Roles which were created by the operator.
+	Roles []NamespaceRoleStatusRole `json:"roles,omitempty"`
+	// ClusterRoleRef is the name of the referenced ClusterRole, which should be
+	// bound by the NamespaceRoleBindings instead of the ClusterRoles / Roles.
+	ClusterRoleRef string `json:"clusterRoleRef,omitempty"`
+	// Namespaces is a list of all existing namespaces selected by the
+	// NamespaceRole.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// MissingNamespaces is a list of namespaces from the Namespaces list, which
+	// do not exist yet. The Roles for these namespaces are created as soon as
+	// the namespaces are created.
+	MissingNamespaces []string `json:"missingNamespaces,omitempty"`
+	// Failures is a list of objects, which couldn't be created, updated or
+	// deleted in the last reconciliation. The operator retries these objects
+	// with a backoff, while all other objects are still reconciled.
+	Failures []NamespaceRoleStatusFailure `json:"failures,omitempty"`
+	// Conditions describe the current state of the NamespaceRole.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// GetScope returns the scope of the NamespaceRole. The scope is required in the
+// v1alpha2 API, so that it is only missing for NamespaceRoles, which were
+// created or updated via the deprecated v1alpha1 API. For these NamespaceRoles
+// the behavior of the v1alpha1 API is kept: The scope is Cluster when the list
+// of namespaces only contains one value, which is equal to "*", and no
+// exclusions are defined. Otherwise the scope is Namespaced.
+func (s NamespaceRoleSpec) GetScope() NamespaceRoleScope {
+	if s.Scope != "" {
+		return s.Scope
+	}
+
+	if len(s.Namespaces) == 1 && s.Namespaces[0] == "*" && len(s.ExcludeNamespaces) == 0 && s.ExcludeNamespaceSelector == nil {
+		return NamespaceRoleScopeCluster
+	}
+
+	return NamespaceRoleScopeNamespaced
+}
+
+type NamespaceRoleStatusRole struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// NamespaceRoleStatusFailure describes an object, which couldn't be created,
+// updated or deleted by the operator.
+type NamespaceRoleStatusFailure struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Message   string `json:"message"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+
+// NamespaceRole is the Schema for the namespaceroles API
+// +kubebuilder:printcolumn:name="Scope",type=string,JSONPath=`.spec.scope`,description="Scope of the NamespaceRole"
+// +kubebuilder:printcolumn:name="Namespaces",type=string,JSONPath=`.spec.namespaces`,description="List of namespaces for which the NamespaceRole is used"
+// +kubebuilder:printcolumn:name="Selector",type=string,JSONPath=`.status.selector`,description="Selector to get all ClusterRoles / Roles created by the operator"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Indicates if all ClusterRoles / Roles were created"
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`,description="Indicates if the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`,description="Indicates if the operator is waiting for namespaces",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Time when this NamespaceRole was created"
+type NamespaceRole struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespaceRoleSpec   `json:"spec,omitempty"`
+	Status NamespaceRoleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceRoleList contains a list of NamespaceRole
+type NamespaceRoleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceRole `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceRole{}, &NamespaceRoleList{})
+}
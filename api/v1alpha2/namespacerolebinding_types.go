@@ -0,0 +1,244 @@
+package v1alpha2
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceRoleBindingSpec defines the desired state of NamespaceRoleBinding
+// +kubebuilder:validation:XValidation:rule="has(self.roleRef) != has(self.roleRefs)",message="exactly one of roleRef or roleRefs is required"
+// +kubebuilder:validation:XValidation:rule="!has(self.validFrom) || !has(self.validUntil) || timestamp(self.validFrom) < timestamp(self.validUntil)",message="validFrom must be before validUntil"
+type NamespaceRoleBindingSpec struct {
+	// RoleRef is a reference to a NamespaceRole, which is used to create all the
+	// ClusterRoleBindings and RoleBindings. These are created based on the status
+	// field of the NamespaceRole.
+	// +optional
+	RoleRef *NamespaceRoleBindingSpecRoleRef `json:"roleRef,omitempty"`
+	// RoleRefs is a list of NamespaceRoles and ClusterRoles, which should be
+	// bound. The names of the created ClusterRoleBindings and RoleBindings are
+	// "<name>-<kind>-<role>", so that the bindings for different roles do not
+	// conflict. It can not be used together with RoleRef.
+	// +optional
+	RoleRefs []NamespaceRoleBindingRoleRef `json:"roleRefs,omitempty"`
+	// Namespaces is a list of namespaces the roles should be bound in. If it or
+	// the NamespaceSelector is set, RoleBindings are only created in the
+	// selected namespaces and ClusterRoles which grant access to all namespaces
+	// are bound via RoleBindings in the selected namespaces instead of a
+	// ClusterRoleBinding. Like in the Namespaces list of a NamespaceRole an entry
+	// can be a glob pattern or a regular expression.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces the roles should be bound in by
+	// their labels. The selected namespaces are added to the namespaces from the
+	// Namespaces list.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Subjects is the list of subjects, which should be bound to the roles. The
+	// name and namespace of a subject can contain the variables "${namespace}"
+	// and "${label:<key>}", which are replaced with the name and the label
+	// values of the namespace of each RoleBinding. Subjects with variables are
+	// not added to ClusterRoleBindings, so that they require the Namespaces or
+	// NamespaceSelector field for roles, which are only bound via
+	// ClusterRoleBindings.
+	Subjects []rbacv1.Subject `json:"subjects"`
+	// ValidFrom is the time from which the roles should be bound. Before this
+	// time no ClusterRoleBindings / RoleBindings are created.
+	// +optional
+	ValidFrom *metav1.Time `json:"validFrom,omitempty"`
+	// ValidUntil is the time until the roles should be bound. Afterwards all
+	// ClusterRoleBindings / RoleBindings are removed, so that the access is
+	// revoked automatically.
+	// +optional
+	ValidUntil *metav1.Time `json:"validUntil,omitempty"`
+	// Schedule defines recurring windows in which the roles should be bound,
+	// e.g. only during business hours. Outside of the windows no
+	// ClusterRoleBindings / RoleBindings are created. The schedule is combined
+	// with ValidFrom and ValidUntil.
+	// +optional
+	Schedule *NamespaceRoleBindingSchedule `json:"schedule,omitempty"`
+	// BreakGlass marks the NamespaceRoleBinding as emergency access. The access
+	// is revoked after the maximum break-glass duration configured for the
+	// operator, even when ValidUntil is later. The activation is reported via a
+	// Warning Event and an audit log entry and the BreakGlass condition is set
+	// until the activation is acknowledged via the
+	// "kobs.io/break-glass-acknowledged" annotation. The maximum duration limits
+	// a single activation. A new activation, e.g. after the
+	// "kobs.io/break-glass-activated-at" annotation was removed, is reported
+	// again.
+	// +optional
+	BreakGlass *NamespaceRoleBindingBreakGlass `json:"breakGlass,omitempty"`
+}
+
+// NamespaceRoleBindingBreakGlass contains the justification for an emergency
+// access.
+type NamespaceRoleBindingBreakGlass struct {
+	// Justification is the reason why the emergency access is required, e.g.
+	// the id of an incident.
+	// +kubebuilder:validation:MinLength=1
+	Justification string `json:"justification"`
+}
+
+// NamespaceRoleBindingSchedule defines recurring windows via a cron expression
+// for the start of a window and the duration of each window.
+type NamespaceRoleBindingSchedule struct {
+	// Cron is a cron expression in the standard format (e.g. "0 9 * * MON-FRI"),
+	// which defines when a window starts.
+	// +kubebuilder:validation:MinLength=1
+	Cron string `json:"cron"`
+	// Duration is the duration of each window, e.g. "8h".
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the name of the time zone (e.g. "Europe/Berlin") the cron
+	// expression is evaluated in. If it is not set, UTC is used.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type NamespaceRoleBindingSpecRoleRef struct {
+	// Name is the name of the NamespaceRole, which should be used by the
+	// NamespaceRoleBinding.
+	Name string `json:"name"`
+}
+
+// NamespaceRoleBindingRoleRefKind is the kind of a role referenced by a
+// NamespaceRoleBinding.
+// +kubebuilder:validation:Enum=NamespaceRole;ClusterRole
+type NamespaceRoleBindingRoleRefKind string
+
+const (
+	// NamespaceRoleBindingRoleRefKindNamespaceRole binds the ClusterRoles and
+	// Roles of a NamespaceRole.
+	NamespaceRoleBindingRoleRefKindNamespaceRole NamespaceRoleBindingRoleRefKind = "NamespaceRole"
+	// NamespaceRoleBindingRoleRefKindClusterRole binds a ClusterRole via a
+	// ClusterRoleBinding.
+	NamespaceRoleBindingRoleRefKindClusterRole NamespaceRoleBindingRoleRefKind = "ClusterRole"
+)
+
+// NamespaceRoleBindingRoleRef is a reference to a NamespaceRole or ClusterRole,
+// which should be bound by a NamespaceRoleBinding.
+type NamespaceRoleBindingRoleRef struct {
+	// +kubebuilder:default=NamespaceRole
+	// +optional
+	Kind NamespaceRoleBindingRoleRefKind `json:"kind,omitempty"`
+	Name string                          `json:"name"`
+}
+
+// GetRoleRefs returns all roles referenced by the NamespaceRoleBinding. If the
+// RoleRef field is set, a list with the referenced NamespaceRole is returned.
+func (s NamespaceRoleBindingSpec) GetRoleRefs() []NamespaceRoleBindingRoleRef {
+	if s.RoleRef != nil {
+		return []NamespaceRoleBindingRoleRef{{
+			Kind: NamespaceRoleBindingRoleRefKindNamespaceRole,
+			Name: s.RoleRef.Name,
+		}}
+	}
+
+	return s.RoleRefs
+}
+
+// HasNamespaces returns true when the NamespaceRoleBinding should only bind the
+// roles in some namespaces.
+func (s NamespaceRoleBindingSpec) HasNamespaces() bool {
+	return len(s.Namespaces) > 0 || s.NamespaceSelector != nil
+}
+
+// NamespaceRoleBindingStatus defines the observed state of NamespaceRoleBinding
+type NamespaceRoleBindingStatus struct {
+	// The label selector to get all ClusterRoleBindings / RoleBindings created by
+	// the operator.
+	Selector string `json:"selector,omitempty"`
+	// ClusterRoleBindings is a list of ClusterRoleBindings which were created by
+	// the operator.
+	ClusterRoleBindings []NamespaceRoleStatusRoleBinding `json:"clusterRoleBindings,omitempty"`
+	// RoleBinding is a list of RoleBindings which were created by the operator.
+	RoleBindings []NamespaceRoleStatusRoleBinding `json:"roleBindings,omitempty"`
+	// RoleRefs is a list of all referenced roles together with the
+	// ClusterRoleBindings and RoleBindings created for them.
+	RoleRefs []NamespaceRoleBindingStatusRoleRef `json:"roleRefs,omitempty"`
+	// Failures is a list of objects, which couldn't be created, updated or
+	// deleted in the last reconciliation. The operator retries these objects
+	// with a backoff, while all other objects are still reconciled.
+	Failures []NamespaceRoleStatusFailure `json:"failures,omitempty"`
+	// NextTransitionTime is the time, when the NamespaceRoleBinding becomes
+	// active or inactive next. It is only set when the access is time-bounded
+	// via the ValidFrom, ValidUntil or Schedule field.
+	// +optional
+	NextTransitionTime *metav1.Time `json:"nextTransitionTime,omitempty"`
+	// BreakGlass contains the activation of the emergency access. It is
+	// derived from the "kobs.io/break-glass-activated-at" annotation, which is
+	// set by the operator, and kept after the BreakGlass field was removed until
+	// the activation was acknowledged.
+	// +optional
+	BreakGlass *NamespaceRoleBindingStatusBreakGlass `json:"breakGlass,omitempty"`
+	// ObservedGeneration is the generation of the NamespaceRoleBinding, which
+	// was reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the NamespaceRoleBinding.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NamespaceRoleBindingStatusBreakGlass is the activation of an emergency
+// access.
+type NamespaceRoleBindingStatusBreakGlass struct {
+	// ActivatedAt is the time when the emergency access was activated. It must
+	// be used as value for the "kobs.io/break-glass-acknowledged" annotation to
+	// acknowledge the activation.
+	ActivatedAt metav1.Time `json:"activatedAt"`
+	// ExpiresAt is the latest time when the emergency access is revoked.
+	ExpiresAt metav1.Time `json:"expiresAt"`
+	// Justification is the justification from the spec.
+	Justification string `json:"justification"`
+}
+
+// NamespaceRoleBindingStatusRoleRef groups the ClusterRoleBindings and
+// RoleBindings created by the operator by the referenced role.
+type NamespaceRoleBindingStatusRoleRef struct {
+	Kind                NamespaceRoleBindingRoleRefKind  `json:"kind"`
+	Name                string                           `json:"name"`
+	ClusterRoleBindings []NamespaceRoleStatusRoleBinding `json:"clusterRoleBindings,omitempty"`
+	RoleBindings        []NamespaceRoleStatusRoleBinding `json:"roleBindings,omitempty"`
+}
+
+type NamespaceRoleStatusRoleBinding struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+
+// NamespaceRoleBinding is the Schema for the namespacerolebindings API
+// +kubebuilder:printcolumn:name="NamespaceRole",type=string,JSONPath=`.spec.roleRef.name`,description="The NamespaceRole used by the NamespaceRoleBinding"
+// +kubebuilder:printcolumn:name="Selector",type=string,JSONPath=`.status.selector`,description="Selector to get all ClusterRoleBindings / RoleBindings created by the operator"
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,description="Indicates if all ClusterRoleBindings / RoleBindings were created"
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.status.conditions[?(@.type=="Active")].status`,description="Indicates if the NamespaceRoleBinding is inside of its validity window"
+// +kubebuilder:printcolumn:name="NextTransition",type=string,JSONPath=`.status.nextTransitionTime`,description="Time when the NamespaceRoleBinding becomes active or inactive next"
+// +kubebuilder:printcolumn:name="BreakGlass",type=string,JSONPath=`.status.conditions[?(@.type=="BreakGlass")].status`,description="Indicates if an emergency access was not acknowledged yet"
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`,description="Indicates if the last reconciliation failed",priority=1
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`,description="Indicates if the operator is waiting for other resources",priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,description="Time when this NamespaceRoleBinding was created"
+type NamespaceRoleBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespaceRoleBindingSpec   `json:"spec,omitempty"`
+	Status NamespaceRoleBindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespaceRoleBindingList contains a list of NamespaceRoleBinding
+type NamespaceRoleBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceRoleBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceRoleBinding{}, &NamespaceRoleBindingList{})
+}
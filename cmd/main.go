@@ -4,9 +4,15 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
+	// Embed the time zone database, so that the time zones of the schedules of
+	// NamespaceRoleBindings can be loaded in images without a tzdata package.
+	_ "time/tzdata"
 
 	kobsiov1alpha1 "github.com/kobsio/namespacerole-operator/api/v1alpha1"
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
 	"github.com/kobsio/namespacerole-operator/internal/controller"
+	webhookv1alpha2 "github.com/kobsio/namespacerole-operator/internal/webhook/v1alpha2"
 
 	// +kubebuilder:scaffold:imports
 
@@ -30,6 +36,7 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(kobsiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kobsiov1alpha2.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -39,6 +46,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var breakGlassMaxTTL time.Duration
 	var tlsOpts []func(*tls.Config)
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -46,6 +54,7 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true, "If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false, "If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&breakGlassMaxTTL, "break-glass-max-ttl", controller.DefaultBreakGlassMaxTTL, "The maximum duration of an emergency access granted via a NamespaceRoleBinding with the breakGlass field.")
 
 	opts := zap.Options{
 		Development: true,
@@ -112,19 +121,41 @@ func main() {
 	}
 
 	if err = (&controller.NamespaceRoleReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("namespacerole-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "NamespaceRole")
 		os.Exit(1)
 	}
 	if err = (&controller.NamespaceRoleBindingReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		Recorder:         mgr.GetEventRecorderFor("namespacerolebinding-controller"),
+		BreakGlassMaxTTL: breakGlassMaxTTL,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Unable to create controller", "controller", "NamespaceRoleBinding")
 		os.Exit(1)
 	}
+	// The admission webhook sets the creator and approvers of an AccessRequest
+	// from the authenticated user. Without it a client could set these fields
+	// itself and approve its own AccessRequest, so that the controller for
+	// AccessRequests is only started together with the webhook.
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&controller.AccessRequestReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "Unable to create controller", "controller", "AccessRequest")
+			os.Exit(1)
+		}
+		if err = webhookv1alpha2.SetupAccessRequestWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Unable to create webhook", "webhook", "AccessRequest")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("Webhooks are disabled, AccessRequests are not reconciled")
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
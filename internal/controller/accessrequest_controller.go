@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// AccessRequestReconciler reconciles an AccessRequest object
+type AccessRequestReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=kobs.io,resources=accessrequests,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kobs.io,resources=accessrequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kobs.io,resources=accessrequests/finalizers,verbs=update
+
+// Reconcile counts the approvals of an AccessRequest. When the AccessRequest
+// has enough valid approvals, a NamespaceRoleBinding owned by the
+// AccessRequest is created, which is only valid for the requested duration.
+// The NamespaceRoleBinding is then reconciled by the
+// NamespaceRoleBindingReconciler like every other NamespaceRoleBinding.
+func (r *AccessRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("Reconcile AccessRequest")
+
+	accessRequest := &kobsiov1alpha2.AccessRequest{}
+	err := r.Get(ctx, req.NamespacedName, accessRequest)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after
+			// reconcile request. Owned objects are automatically garbage
+			// collected. For additional cleanup logic use finalizers. Return
+			// and don't requeue
+			return ctrl.Result{}, nil
+		}
+
+		log.Error(err, "Failed to get AccessRequest")
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	waitingFor, reconcileErr := r.reconcileAccessRequest(ctx, accessRequest, now)
+
+	accessRequest.Status.ObservedGeneration = accessRequest.Generation
+	setConditions(&accessRequest.Status.Conditions, accessRequest.Generation, reconcileErr, waitingFor)
+
+	err = r.Status().Update(ctx, accessRequest)
+	if err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	// Requeue the request when the access expires, so that the phase is
+	// changed to Expired. The access itself is revoked by the
+	// NamespaceRoleBindingReconciler via the validUntil field.
+	var result ctrl.Result
+	if accessRequest.Status.Phase == kobsiov1alpha2.AccessRequestPhaseApproved && accessRequest.Status.ExpiresAt != nil {
+		result.RequeueAfter = accessRequest.Status.ExpiresAt.Sub(now)
+	}
+
+	return result, reconcileErr
+}
+
+// reconcileAccessRequest sets the phase of the provided AccessRequest based on
+// its approvals and creates the NamespaceRoleBinding when it is approved. The
+// status is not written to the API server, this is done by the caller. If the
+// AccessRequest is still waiting for approvals, a message for the Progressing
+// condition is returned.
+//
+// The phase, the time of the approval and the expiration are always computed
+// from the approvals in the spec and never read from the status, so that a
+// denied or expired AccessRequest can not be approved again and the access can
+// not be extended by changing the status.
+func (r *AccessRequestReconciler) reconcileAccessRequest(ctx context.Context, accessRequest *kobsiov1alpha2.AccessRequest, now time.Time) (string, error) {
+	if accessRequest.Spec.RequestedBy == "" {
+		accessRequest.Status.Phase = kobsiov1alpha2.AccessRequestPhasePending
+		accessRequest.Status.ApprovedBy = nil
+		accessRequest.Status.ApprovedAt = nil
+		accessRequest.Status.ExpiresAt = nil
+		return "", fmt.Errorf("the creator of the AccessRequest is unknown, the admission webhook of the operator must be enabled")
+	}
+
+	requiredApprovals := accessRequest.Spec.RequiredApprovals
+	if requiredApprovals < 1 {
+		requiredApprovals = 1
+	}
+
+	approvedBy, approvedAt, denied := countApprovals(ctx, accessRequest, requiredApprovals)
+	accessRequest.Status.ApprovedBy = approvedBy
+
+	// A single valid denial denies the AccessRequest, even when it was already
+	// approved. In this case we also remove the NamespaceRoleBinding, so that
+	// the access is revoked immediately. Since approvals can not be removed,
+	// a denied AccessRequest stays denied.
+	if denied {
+		accessRequest.Status.Phase = kobsiov1alpha2.AccessRequestPhaseDenied
+		return "", r.deleteNamespaceRoleBinding(ctx, accessRequest)
+	}
+
+	if approvedAt == nil {
+		accessRequest.Status.Phase = kobsiov1alpha2.AccessRequestPhasePending
+		accessRequest.Status.ApprovedAt = nil
+		accessRequest.Status.ExpiresAt = nil
+		return fmt.Sprintf("Waiting for %d more approvals", requiredApprovals-len(approvedBy)), nil
+	}
+
+	// The time of the approval is the time of the approval, which reached the
+	// required number of approvals, so that the access can not be extended by
+	// adding more approvals.
+	accessRequest.Status.ApprovedAt = approvedAt
+	accessRequest.Status.ExpiresAt = &metav1.Time{Time: approvedAt.Add(accessRequest.Spec.Duration.Duration)}
+
+	if !now.Before(accessRequest.Status.ExpiresAt.Time) {
+		accessRequest.Status.Phase = kobsiov1alpha2.AccessRequestPhaseExpired
+		return "", nil
+	}
+
+	accessRequest.Status.Phase = kobsiov1alpha2.AccessRequestPhaseApproved
+	return "", r.createNamespaceRoleBinding(ctx, accessRequest)
+}
+
+// countApprovals returns the users who approved the provided AccessRequest,
+// the time of the approval with which the required number of approvals was
+// reached and if the AccessRequest was denied. The decisions were already
+// checked by the admission webhook when they were added, but we still ignore
+// decisions without a user or time and decisions of the creator or a subject of
+// the AccessRequest, so that nobody can approve their own request.
+func countApprovals(ctx context.Context, accessRequest *kobsiov1alpha2.AccessRequest, requiredApprovals int) ([]string, *metav1.Time, bool) {
+	log := log.FromContext(ctx)
+
+	var approvedBy []string
+	var approvedAt *metav1.Time
+	denied := false
+
+	for _, approval := range accessRequest.Spec.Approvals {
+		if approval.User == "" || approval.Time.IsZero() {
+			log.Info("Ignore approval, which was not admitted by the webhook")
+			continue
+		}
+		if approval.User == accessRequest.Spec.RequestedBy || isAccessRequestSubject(accessRequest, approval.User) {
+			log.Info("Ignore approval by creator or subject of the AccessRequest", "User", approval.User)
+			continue
+		}
+
+		switch approval.Decision {
+		case kobsiov1alpha2.AccessRequestDecisionApproved:
+			approvedBy = appendUnique(approvedBy, approval.User)
+			if approvedAt == nil && len(approvedBy) >= requiredApprovals {
+				approvedAt = approval.Time.DeepCopy()
+			}
+		case kobsiov1alpha2.AccessRequestDecisionDenied:
+			denied = true
+		}
+	}
+
+	return approvedBy, approvedAt, denied
+}
+
+// isAccessRequestSubject returns true when the provided user is one of the
+// subjects of the AccessRequest. Members of a requested group are rejected by
+// the admission webhook, because the groups of a user are only known there.
+func isAccessRequestSubject(accessRequest *kobsiov1alpha2.AccessRequest, user string) bool {
+	for _, subject := range accessRequest.Spec.Subjects {
+		if subject.Kind == "User" && subject.Name == user {
+			return true
+		}
+		if subject.Kind == "ServiceAccount" && fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name) == user {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAccessRequestBindingName returns the name of the NamespaceRoleBinding
+// created for the provided AccessRequest.
+func getAccessRequestBindingName(accessRequest *kobsiov1alpha2.AccessRequest) string {
+	return fmt.Sprintf("accessrequest-%s", accessRequest.Name)
+}
+
+// createNamespaceRoleBinding creates or updates the NamespaceRoleBinding for
+// the provided approved AccessRequest. The NamespaceRoleBinding is only valid
+// until the AccessRequest expires. If a NamespaceRoleBinding with the same name
+// exists, which is not owned by the AccessRequest, an error is returned instead
+// of taking it over.
+func (r *AccessRequestReconciler) createNamespaceRoleBinding(ctx context.Context, accessRequest *kobsiov1alpha2.AccessRequest) error {
+	namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: getAccessRequestBindingName(accessRequest),
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, namespaceRoleBinding, func() error {
+		if !namespaceRoleBinding.CreationTimestamp.IsZero() && !metav1.IsControlledBy(namespaceRoleBinding, accessRequest) {
+			return fmt.Errorf("NamespaceRoleBinding %q is not owned by the AccessRequest", namespaceRoleBinding.Name)
+		}
+
+		namespaceRoleBinding.Spec = kobsiov1alpha2.NamespaceRoleBindingSpec{
+			RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+				Name: accessRequest.Spec.RoleRef.Name,
+			},
+			Namespaces: accessRequest.Spec.Namespaces,
+			Subjects:   accessRequest.Spec.Subjects,
+			ValidUntil: accessRequest.Status.ExpiresAt,
+		}
+
+		return ctrl.SetControllerReference(accessRequest, namespaceRoleBinding, r.Scheme)
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to create or update NamespaceRoleBinding", "NamespaceRoleBinding.Name", namespaceRoleBinding.Name)
+		return err
+	}
+
+	accessRequest.Status.NamespaceRoleBinding = namespaceRoleBinding.Name
+	return nil
+}
+
+// deleteNamespaceRoleBinding deletes the NamespaceRoleBinding of the provided
+// AccessRequest, when it was already created.
+func (r *AccessRequestReconciler) deleteNamespaceRoleBinding(ctx context.Context, accessRequest *kobsiov1alpha2.AccessRequest) error {
+	if accessRequest.Status.NamespaceRoleBinding == "" {
+		return nil
+	}
+
+	namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+	if err := r.Get(ctx, types.NamespacedName{Name: accessRequest.Status.NamespaceRoleBinding}, namespaceRoleBinding); err != nil {
+		if errors.IsNotFound(err) {
+			accessRequest.Status.NamespaceRoleBinding = ""
+			return nil
+		}
+		return err
+	}
+
+	if metav1.IsControlledBy(namespaceRoleBinding, accessRequest) {
+		if err := r.Delete(ctx, namespaceRoleBinding); err != nil && !errors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to delete NamespaceRoleBinding", "NamespaceRoleBinding.Name", namespaceRoleBinding.Name)
+			return err
+		}
+	}
+
+	accessRequest.Status.NamespaceRoleBinding = ""
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AccessRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kobsiov1alpha2.AccessRequest{}).
+		Owns(&kobsiov1alpha2.NamespaceRoleBinding{}).
+		Complete(r)
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultBreakGlassMaxTTL is the default for the maximum duration of an
+	// emergency access granted via a NamespaceRoleBinding.
+	DefaultBreakGlassMaxTTL = time.Hour
+
+	// breakGlassActivatedAnnotation is the annotation, which is set by the
+	// operator to the activation time of an emergency access. The maximum
+	// break-glass duration is calculated from this time. It is an annotation,
+	// because the metadata can not be changed via the status subresource, so
+	// that the activation can not be reset by a client with write access to the
+	// status.
+	breakGlassActivatedAnnotation = "kobs.io/break-glass-activated-at"
+
+	// breakGlassAcknowledgedAnnotation is the annotation to acknowledge the
+	// activation of an emergency access. The value must be the activation time
+	// from the status of the NamespaceRoleBinding.
+	breakGlassAcknowledgedAnnotation = "kobs.io/break-glass-acknowledged"
+)
+
+// reconcileBreakGlass records the activation of an emergency access in the
+// "kobs.io/break-glass-activated-at" annotation of the provided
+// NamespaceRoleBinding and returns the spec, which should be used to check the
+// validity. For an emergency access the ValidUntil field of the returned spec is
+// limited to the maximum break-glass duration after the activation, so that
+// the access can not linger.
+//
+// The activation is saved before it is reported via a Warning Event and an
+// audit log entry, so that a failed reconciliation doesn't report it again and
+// doesn't extend the access. The status is always derived from the annotation.
+// The activation is kept until the BreakGlass field was removed and the
+// activation was acknowledged, so that a new activation is reported again.
+func (r *NamespaceRoleBindingReconciler) reconcileBreakGlass(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, now time.Time) (kobsiov1alpha2.NamespaceRoleBindingSpec, error) {
+	spec := namespaceRoleBinding.Spec
+	activatedAt, activated := getBreakGlassActivation(namespaceRoleBinding, now)
+
+	maxTTL := r.BreakGlassMaxTTL
+	if maxTTL <= 0 {
+		maxTTL = DefaultBreakGlassMaxTTL
+	}
+
+	if spec.BreakGlass == nil {
+		if !activated {
+			namespaceRoleBinding.Status.BreakGlass = nil
+			return spec, nil
+		}
+
+		justification := ""
+		if namespaceRoleBinding.Status.BreakGlass != nil {
+			justification = namespaceRoleBinding.Status.BreakGlass.Justification
+		}
+		namespaceRoleBinding.Status.BreakGlass = &kobsiov1alpha2.NamespaceRoleBindingStatusBreakGlass{
+			ActivatedAt:   metav1.Time{Time: activatedAt},
+			ExpiresAt:     metav1.Time{Time: activatedAt.Add(maxTTL)},
+			Justification: justification,
+		}
+
+		if isBreakGlassAcknowledged(namespaceRoleBinding) {
+			if err := r.setBreakGlassActivation(ctx, namespaceRoleBinding, ""); err != nil {
+				return spec, err
+			}
+			namespaceRoleBinding.Status.BreakGlass = nil
+		}
+
+		return spec, nil
+	}
+
+	if !activated {
+		if value, ok := namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation]; ok {
+			log.FromContext(ctx).Info("Replace invalid break-glass activation", "Value", value)
+		}
+
+		// The activation can not be before the creation of the
+		// NamespaceRoleBinding, even when the clock of the operator is behind
+		// the clock of the API server.
+		activatedAt = now.Truncate(time.Second)
+		if activatedAt.Before(namespaceRoleBinding.CreationTimestamp.Time) {
+			activatedAt = namespaceRoleBinding.CreationTimestamp.Time
+		}
+		if err := r.setBreakGlassActivation(ctx, namespaceRoleBinding, activatedAt.UTC().Format(time.RFC3339)); err != nil {
+			return spec, err
+		}
+
+		expiresAt := activatedAt.Add(maxTTL).UTC().Format(time.RFC3339)
+		log.FromContext(ctx).Info(
+			"Break-glass access activated",
+			"audit", "break-glass",
+			"NamespaceRoleBinding.Name", namespaceRoleBinding.Name,
+			"Justification", spec.BreakGlass.Justification,
+			"RoleRefs", spec.GetRoleRefs(),
+			"Subjects", spec.Subjects,
+			"ExpiresAt", expiresAt,
+		)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(namespaceRoleBinding, corev1.EventTypeWarning, "BreakGlass", "Break-glass access activated until %s: %s", expiresAt, spec.BreakGlass.Justification)
+		}
+	}
+
+	namespaceRoleBinding.Status.BreakGlass = &kobsiov1alpha2.NamespaceRoleBindingStatusBreakGlass{
+		ActivatedAt:   metav1.Time{Time: activatedAt},
+		ExpiresAt:     metav1.Time{Time: activatedAt.Add(maxTTL)},
+		Justification: spec.BreakGlass.Justification,
+	}
+
+	expiresAt := namespaceRoleBinding.Status.BreakGlass.ExpiresAt
+	if spec.ValidUntil == nil || expiresAt.Before(spec.ValidUntil) {
+		spec.ValidUntil = &expiresAt
+	}
+
+	return spec, nil
+}
+
+// getBreakGlassActivation returns the activation time of the emergency access
+// from the "kobs.io/break-glass-activated-at" annotation. If the annotation is
+// missing or invalid, false is returned, so that a new activation is recorded.
+// The annotation can also be set by everyone who can edit the
+// NamespaceRoleBinding, so that an activation before the creation of the
+// NamespaceRoleBinding or after the provided time is invalid. Otherwise an
+// activation in the future would never be reported and would extend the access.
+func getBreakGlassActivation(namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, now time.Time) (time.Time, bool) {
+	value, ok := namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	activatedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	createdAt := namespaceRoleBinding.CreationTimestamp.Time
+	if activatedAt.Before(createdAt) || (activatedAt.After(now) && activatedAt.After(createdAt)) {
+		return time.Time{}, false
+	}
+
+	return activatedAt, true
+}
+
+// setBreakGlassActivation sets the "kobs.io/break-glass-activated-at" annotation
+// of the provided NamespaceRoleBinding to the provided value or removes it,
+// when the value is empty. The patch uses an optimistic lock, so that an
+// activation is only saved once.
+func (r *NamespaceRoleBindingReconciler) setBreakGlassActivation(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, value string) error {
+	patch := client.MergeFromWithOptions(namespaceRoleBinding.DeepCopy(), client.MergeFromWithOptimisticLock{})
+
+	if value == "" {
+		delete(namespaceRoleBinding.Annotations, breakGlassActivatedAnnotation)
+	} else {
+		if namespaceRoleBinding.Annotations == nil {
+			namespaceRoleBinding.Annotations = map[string]string{}
+		}
+		namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation] = value
+	}
+
+	// The patch overwrites the object with the response of the API server, so
+	// we have to keep the status, which is written by the caller afterwards.
+	status := namespaceRoleBinding.Status.DeepCopy()
+	if err := r.Patch(ctx, namespaceRoleBinding, patch); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to save break-glass activation")
+		return err
+	}
+	namespaceRoleBinding.Status = *status
+
+	return nil
+}
+
+// isBreakGlassAcknowledged returns true when the activation of the emergency
+// access from the status of the NamespaceRoleBinding was acknowledged via the
+// "kobs.io/break-glass-acknowledged" annotation.
+func isBreakGlassAcknowledged(namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) bool {
+	if namespaceRoleBinding.Status.BreakGlass == nil {
+		return false
+	}
+
+	return namespaceRoleBinding.Annotations[breakGlassAcknowledgedAnnotation] == namespaceRoleBinding.Status.BreakGlass.ActivatedAt.UTC().Format(time.RFC3339)
+}
+
+// setBreakGlassCondition sets the BreakGlass condition based on the activation
+// of the emergency access in the status of the NamespaceRoleBinding. The
+// condition is removed when there is no activation.
+func setBreakGlassCondition(conditions *[]metav1.Condition, generation int64, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) {
+	breakGlass := namespaceRoleBinding.Status.BreakGlass
+	if breakGlass == nil {
+		meta.RemoveStatusCondition(conditions, kobsiov1alpha2.ConditionTypeBreakGlass)
+		return
+	}
+
+	activatedAt := breakGlass.ActivatedAt.UTC().Format(time.RFC3339)
+	if isBreakGlassAcknowledged(namespaceRoleBinding) {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeBreakGlass,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "Acknowledged",
+			Message:            fmt.Sprintf("The break-glass access activated at %s was acknowledged", activatedAt),
+		})
+		return
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kobsiov1alpha2.ConditionTypeBreakGlass,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "Unacknowledged",
+		Message:            fmt.Sprintf("Break-glass access was activated at %s with the justification %q, acknowledge it via the annotation %s=%s", activatedAt, breakGlass.Justification, breakGlassAcknowledgedAnnotation, activatedAt),
+	})
+}
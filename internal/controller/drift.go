@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// hashAnnotation is the annotation, which contains the hash of the rules
+	// of a generated ClusterRole / Role or the roleRef and subjects of a
+	// generated ClusterRoleBinding / RoleBinding, as it was written by the
+	// operator. It is used to detect changes made outside of the operator.
+	hashAnnotation = "kobs.io/hash"
+)
+
+var (
+	driftCorrectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "namespacerole_operator_drift_corrections_total",
+		Help: "Number of generated ClusterRoles, Roles, ClusterRoleBindings and RoleBindings, which were changed or deleted outside of the operator and restored.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftCorrectionsTotal)
+}
+
+// hashRules returns the hash of the provided rules for the hash annotation.
+func hashRules(rules []rbacv1.PolicyRule) string {
+	if len(rules) == 0 {
+		rules = nil
+	}
+
+	return computeHash(rules)
+}
+
+// hashBinding returns the hash of the provided roleRef and subjects for the
+// hash annotation. The API group of User and Group subjects is defaulted like
+// it is done by the API server, so that the defaulting isn't detected as
+// drift.
+func hashBinding(roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) string {
+	var defaultedSubjects []rbacv1.Subject
+	for _, subject := range subjects {
+		if subject.APIGroup == "" && (subject.Kind == rbacv1.UserKind || subject.Kind == rbacv1.GroupKind) {
+			subject.APIGroup = rbacv1.GroupName
+		}
+		defaultedSubjects = append(defaultedSubjects, subject)
+	}
+	subjects = defaultedSubjects
+
+	return computeHash(struct {
+		RoleRef  rbacv1.RoleRef   `json:"roleRef"`
+		Subjects []rbacv1.Subject `json:"subjects"`
+	}{roleRef, subjects})
+}
+
+func computeHash(value any) string {
+	data, _ := json.Marshal(value)
+
+	hash := fnv.New64a()
+	hash.Write(data)
+
+	return fmt.Sprintf("%016x", hash.Sum64())
+}
+
+// hasDrifted returns true when the provided existing object was changed outside
+// of the operator, so that its content doesn't match the hash annotation
+// anymore. Objects without the annotation, e.g. objects created by an older
+// version of the operator, are not considered as drifted.
+func hasDrifted(obj metav1.Object, hash string) bool {
+	if obj.GetCreationTimestamp().Time.IsZero() {
+		return false
+	}
+
+	existingHash, ok := obj.GetAnnotations()[hashAnnotation]
+	return ok && existingHash != hash
+}
+
+// setHash sets the hash annotation on the provided object. Other annotations
+// are kept.
+func setHash(obj metav1.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[hashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+}
+
+// createOrUpdateWithDrift creates or updates the provided generated object and
+// reports when it was changed or deleted outside of the operator. Before the
+// object is updated, the hash of its current content is compared with the hash
+// annotation. An object which was processed in the last reconciliation (and
+// therefore is listed in the status of the owner), but has to be created, was
+// deleted outside of the operator.
+//
+// The mutate function must set the desired content of the object and the hash
+// function must return the hash of the current content of the object, which is
+// then written to the hash annotation.
+func createOrUpdateWithDrift(ctx context.Context, c client.Client, recorder record.EventRecorder, owner runtime.Object, kind string, obj client.Object, wasProcessed bool, hash func() string, mutate func() error) error {
+	drifted := false
+	operationResult, err := controllerutil.CreateOrUpdate(ctx, c, obj, func() error {
+		drifted = hasDrifted(obj, hash())
+		if err := mutate(); err != nil {
+			return err
+		}
+		setHash(obj, hash())
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if drifted || (operationResult == controllerutil.OperationResultCreated && wasProcessed) {
+		recordDrift(ctx, recorder, owner, kind, obj.GetNamespace(), obj.GetName())
+	}
+
+	return nil
+}
+
+// recordDrift reports that the generated object with the provided kind,
+// namespace and name was changed or deleted outside of the operator and was
+// restored. The drift is reported via a Warning Event for the owner, a log
+// entry and the drift metric.
+func recordDrift(ctx context.Context, recorder record.EventRecorder, owner runtime.Object, kind, namespace, name string) {
+	log.FromContext(ctx).Info("Restored object changed outside of the operator", "Kind", kind, "Namespace", namespace, "Name", name)
+	driftCorrectionsTotal.WithLabelValues(kind).Inc()
+
+	if recorder != nil {
+		if namespace != "" {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "DriftDetected", "Restored %s %s/%s, which was changed outside of the operator", kind, namespace, name)
+		} else {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "DriftDetected", "Restored %s %s, which was changed outside of the operator", kind, name)
+		}
+	}
+}
+
+// hasLabelPredicate returns a predicate, which only accepts objects with the
+// provided label key. For updates the old and the new object are checked, so
+// that we also notice when the label is removed from a generated object.
+func hasLabelPredicate(key string) predicate.Predicate {
+	hasLabel := func(obj metav1.Object) bool {
+		_, ok := obj.GetLabels()[key]
+		return ok
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return hasLabel(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return hasLabel(e.ObjectOld) || hasLabel(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return hasLabel(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return hasLabel(e.Object)
+		},
+	}
+}
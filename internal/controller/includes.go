@@ -0,0 +1,251 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// includesIndexKey is the name of the field index for the includes of a
+	// NamespaceRole. The indexed values have the format "<kind>/<name>".
+	includesIndexKey = ".spec.includes"
+)
+
+// includeIndexValue returns the value of the includes field index for the
+// provided kind and name.
+func includeIndexValue(kind kobsiov1alpha2.NamespaceRoleIncludeKind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// indexIncludes is the indexer function for the includes field index.
+func indexIncludes(obj client.Object) []string {
+	namespaceRole, ok := obj.(*kobsiov1alpha2.NamespaceRole)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, include := range namespaceRole.Spec.Includes {
+		values = append(values, includeIndexValue(include.Kind, include.Name))
+	}
+
+	return values
+}
+
+// resolveRules returns the rules of the provided NamespaceRole merged with the
+// rules of all included NamespaceRoles and ClusterRoles. Included NamespaceRoles
+// are resolved recursively. If a NamespaceRole includes itself directly or
+// indirectly an error containing the cycle is returned.
+func (r *NamespaceRoleReconciler) resolveRules(ctx context.Context, namespaceRole *kobsiov1alpha2.NamespaceRole) ([]rbacv1.PolicyRule, error) {
+	return r.collectRules(ctx, namespaceRole, []string{namespaceRole.Name})
+}
+
+func (r *NamespaceRoleReconciler) collectRules(ctx context.Context, namespaceRole *kobsiov1alpha2.NamespaceRole, path []string) ([]rbacv1.PolicyRule, error) {
+	rules := appendUniqueRules(nil, namespaceRole.Spec.Rules...)
+
+	// An included NamespaceRole can reference an existing ClusterRole instead
+	// of defining its own rules. In this case the rules of the ClusterRole are
+	// included.
+	if namespaceRole.Spec.ClusterRoleRef != "" && len(path) > 1 {
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := r.Get(ctx, types.NamespacedName{Name: namespaceRole.Spec.ClusterRoleRef}, clusterRole); err != nil {
+			return nil, fmt.Errorf("failed to get ClusterRole %q referenced by NamespaceRole %q: %w", namespaceRole.Spec.ClusterRoleRef, namespaceRole.Name, err)
+		}
+
+		rules = appendUniqueRules(rules, clusterRole.Rules...)
+	}
+
+	if namespaceRole.Spec.RuleSelector != nil {
+		selectedRules, err := r.selectRules(ctx, namespaceRole.Spec.RuleSelector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select ClusterRoles for NamespaceRole %q: %w", namespaceRole.Name, err)
+		}
+
+		rules = appendUniqueRules(rules, selectedRules...)
+	}
+
+	for _, include := range namespaceRole.Spec.Includes {
+		switch include.Kind {
+		case kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole:
+			clusterRole := &rbacv1.ClusterRole{}
+			if err := r.Get(ctx, types.NamespacedName{Name: include.Name}, clusterRole); err != nil {
+				return nil, fmt.Errorf("failed to get included ClusterRole %q: %w", include.Name, err)
+			}
+
+			rules = appendUniqueRules(rules, clusterRole.Rules...)
+
+		case kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole:
+			if contains(path, include.Name) {
+				return nil, fmt.Errorf("include cycle detected: %s", strings.Join(append(path, include.Name), " -> "))
+			}
+
+			includedNamespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			if err := r.Get(ctx, types.NamespacedName{Name: include.Name}, includedNamespaceRole); err != nil {
+				return nil, fmt.Errorf("failed to get included NamespaceRole %q: %w", include.Name, err)
+			}
+
+			includedPath := append(append([]string{}, path...), include.Name)
+			includedRules, err := r.collectRules(ctx, includedNamespaceRole, includedPath)
+			if err != nil {
+				return nil, err
+			}
+
+			rules = appendUniqueRules(rules, includedRules...)
+
+		default:
+			return nil, fmt.Errorf("invalid kind %q for include %q", include.Kind, include.Name)
+		}
+	}
+
+	return rules, nil
+}
+
+// selectRules returns the rules of all ClusterRoles matching the provided
+// label selector in alphabetical order of the ClusterRole names. ClusterRoles
+// created by the operator are skipped, so that a NamespaceRole can not select
+// its own rules.
+func (r *NamespaceRoleReconciler) selectRules(ctx context.Context, labelSelector *metav1.LabelSelector) ([]rbacv1.PolicyRule, error) {
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterRoles := &rbacv1.ClusterRoleList{}
+	if err := r.List(ctx, clusterRoles, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(clusterRoles.Items, func(i, j int) bool {
+		return clusterRoles.Items[i].Name < clusterRoles.Items[j].Name
+	})
+
+	var rules []rbacv1.PolicyRule
+	for _, clusterRole := range clusterRoles.Items {
+		if _, ok := clusterRole.Labels[selectorLabelKeyNR]; ok {
+			continue
+		}
+
+		rules = appendUniqueRules(rules, clusterRole.Rules...)
+	}
+
+	return rules, nil
+}
+
+// appendUniqueRules appends all rules to the provided list, which are not
+// already part of it, so that rules included via multiple paths are only added
+// once.
+func appendUniqueRules(list []rbacv1.PolicyRule, rules ...rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	for _, rule := range rules {
+		exists := false
+		for _, item := range list {
+			if equality.Semantic.DeepEqual(item, rule) {
+				exists = true
+				break
+			}
+		}
+
+		if !exists {
+			list = append(list, rule)
+		}
+	}
+
+	return list
+}
+
+// findIncludingNamespaceRoles returns the names of all NamespaceRoles, which
+// include the provided object directly or indirectly via other NamespaceRoles.
+// The lookup uses the includes field index, so that we do not have to resolve
+// the includes of all NamespaceRoles.
+func (r *NamespaceRoleReconciler) findIncludingNamespaceRoles(ctx context.Context, kind kobsiov1alpha2.NamespaceRoleIncludeKind, name string) ([]string, error) {
+	var names []string
+	queue := []string{includeIndexValue(kind, name)}
+
+	for len(queue) > 0 {
+		value := queue[0]
+		queue = queue[1:]
+
+		namespaceRoles := &kobsiov1alpha2.NamespaceRoleList{}
+		if err := r.List(ctx, namespaceRoles, client.MatchingFields{includesIndexKey: value}); err != nil {
+			return nil, err
+		}
+
+		for _, namespaceRole := range namespaceRoles.Items {
+			if contains(names, namespaceRole.Name) {
+				continue
+			}
+
+			names = append(names, namespaceRole.Name)
+			queue = append(queue, includeIndexValue(kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole, namespaceRole.Name))
+		}
+	}
+
+	return names, nil
+}
+
+// findNamespaceRolesForClusterRole returns a reconcile request for all
+// NamespaceRoles which include the ClusterRole or select it via their rule
+// selector. Since the map function is called with the old and the new object
+// on updates, NamespaceRoles are also reconciled when the labels of a
+// ClusterRole are changed, so that it doesn't match the selector anymore.
+func (r *NamespaceRoleReconciler) findNamespaceRolesForClusterRole(ctx context.Context, clusterRole client.Object) []reconcile.Request {
+	if _, ok := clusterRole.GetLabels()[selectorLabelKeyNR]; ok {
+		return r.includeRequests(ctx, kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole, clusterRole.GetName())
+	}
+
+	namespaceRoles := &kobsiov1alpha2.NamespaceRoleList{}
+	if err := r.List(ctx, namespaceRoles); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NamespaceRoles", "ClusterRole.Name", clusterRole.GetName())
+		return nil
+	}
+
+	requests := r.includeRequests(ctx, kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole, clusterRole.GetName())
+	for _, namespaceRole := range namespaceRoles.Items {
+		if namespaceRole.Spec.RuleSelector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(namespaceRole.Spec.RuleSelector)
+		if err != nil || !selector.Matches(labels.Set(clusterRole.GetLabels())) {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceRole.Name}})
+		requests = append(requests, r.includeRequests(ctx, kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole, namespaceRole.Name)...)
+	}
+
+	return requests
+}
+
+// findNamespaceRolesForNamespaceRole returns a reconcile request for all
+// NamespaceRoles which include the NamespaceRole.
+func (r *NamespaceRoleReconciler) findNamespaceRolesForNamespaceRole(ctx context.Context, namespaceRole client.Object) []reconcile.Request {
+	return r.includeRequests(ctx, kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole, namespaceRole.GetName())
+}
+
+func (r *NamespaceRoleReconciler) includeRequests(ctx context.Context, kind kobsiov1alpha2.NamespaceRoleIncludeKind, name string) []reconcile.Request {
+	names, err := r.findIncludingNamespaceRoles(ctx, kind, name)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list including NamespaceRoles", "Kind", kind, "Name", name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, name := range names {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+
+	return requests
+}
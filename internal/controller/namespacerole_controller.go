@@ -3,20 +3,28 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 
-	kobsiov1alpha1 "github.com/kobsio/namespacerole-operator/api/v1alpha1"
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
 
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -26,13 +34,16 @@ const (
 // NamespaceRoleReconciler reconciles a NamespaceRole object
 type NamespaceRoleReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=kobs.io,resources=namespaceroles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kobs.io,resources=namespaceroles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kobs.io,resources=namespaceroles/finalizers,verbs=update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;roles,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state. For more
@@ -42,7 +53,7 @@ func (r *NamespaceRoleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	log := log.FromContext(ctx)
 	log.Info("Reconcile NamespaceRole")
 
-	namespaceRole := &kobsiov1alpha1.NamespaceRole{}
+	namespaceRole := &kobsiov1alpha2.NamespaceRole{}
 	err := r.Get(ctx, req.NamespacedName, namespaceRole)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -57,99 +68,226 @@ func (r *NamespaceRoleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	var processedClusterRoles []kobsiov1alpha1.NamespaceRoleStatusRole
-	var processedRoles []kobsiov1alpha1.NamespaceRoleStatusRole
+	// Reconcile the ClusterRoles / Roles of the NamespaceRole and set the
+	// conditions based on the result, so that failures are not only visible in
+	// the logs of the operator.
+	reconcileErr := r.reconcileRoles(ctx, namespaceRole)
 
-	// If the list of namespaces is empty, we don't need to create any
-	// ClusterRoles or Roles, so we can return early.
-	if len(namespaceRole.Spec.Namespaces) == 0 {
-		log.Info("No namespaces defined")
-		return ctrl.Result{}, nil
+	var waitingFor string
+	if len(namespaceRole.Status.MissingNamespaces) > 0 {
+		waitingFor = fmt.Sprintf("Waiting for namespaces: %s", strings.Join(namespaceRole.Status.MissingNamespaces, ", "))
 	}
 
-	// If the NamespaceRole only contains one namespace, which is equal to "*",
-	// we create a ClusterRole instead of a Role.
-	if len(namespaceRole.Spec.Namespaces) == 1 && namespaceRole.Spec.Namespaces[0] == "*" {
-		clusterRole := &rbacv1.ClusterRole{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespaceRole.Name,
-				Labels: map[string]string{
-					selectorLabelKeyNR: namespaceRole.Name,
-				},
-			},
-			Rules: namespaceRole.Spec.Rules,
+	namespaceRole.Status.ObservedGeneration = namespaceRole.Generation
+	setConditions(&namespaceRole.Status.Conditions, namespaceRole.Generation, reconcileErr, waitingFor)
+
+	err = r.Status().Update(ctx, namespaceRole)
+	if err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, reconcileErr
+}
+
+// reconcileRoles creates, updates and deletes the ClusterRoles / Roles for the
+// provided NamespaceRole and sets the corresponding status fields. The status
+// is not written to the API server, this is done by the caller.
+//
+// A failure in one namespace doesn't stop the reconciliation of the other
+// namespaces. All errors are aggregated and returned, so that the request is
+// requeued with a backoff. Since unchanged ClusterRoles / Roles are not
+// updated, only the failed objects are written again in the next run.
+func (r *NamespaceRoleReconciler) reconcileRoles(ctx context.Context, namespaceRole *kobsiov1alpha2.NamespaceRole) error {
+	log := log.FromContext(ctx)
+
+	var processedClusterRoles []kobsiov1alpha2.NamespaceRoleStatusRole
+	var processedRoles []kobsiov1alpha2.NamespaceRoleStatusRole
+	var missingNamespaces []string
+	var failures []kobsiov1alpha2.NamespaceRoleStatusFailure
+	var errs []error
+
+	// Merge the rules of all included NamespaceRoles and ClusterRoles into the
+	// rules of the NamespaceRole. If an include can not be resolved (e.g.
+	// because of a cycle), we keep the existing ClusterRoles / Roles, so that
+	// a mistake in an include doesn't remove the access of all users.
+	rules, err := r.resolveRules(ctx, namespaceRole)
+	if err != nil {
+		log.Error(err, "Failed to resolve includes")
+		namespaceRole.Status.Failures = []kobsiov1alpha2.NamespaceRoleStatusFailure{newFailure("NamespaceRole", "", namespaceRole.Name, err)}
+		return err
+	}
+
+	// Decide based on the scope of the NamespaceRole which rules are added to
+	// the ClusterRole and which rules are added to the Roles. For the scopes
+	// Namespaced and Both, the rules for cluster-scoped resources are added to
+	// the ClusterRole and all other rules are added to the Roles. If the scope
+	// is not set, because the NamespaceRole was created via the v1alpha1 API,
+	// all rules are added to the Roles like before, so that rules which had no
+	// effect in a Role do not grant cluster-wide access after an upgrade.
+	scope := namespaceRole.Spec.GetScope()
+	var clusterRoleRules []rbacv1.PolicyRule
+	var roleRules []rbacv1.PolicyRule
+	switch {
+	case scope == kobsiov1alpha2.NamespaceRoleScopeCluster:
+		clusterRoleRules = rules
+	case namespaceRole.Spec.Scope == "":
+		roleRules = rules
+	default:
+		clusterRoleRules, roleRules = splitRules(r.RESTMapper(), rules)
+	}
+
+	var existingNamespaces []string
+
+	if scope != kobsiov1alpha2.NamespaceRoleScopeCluster {
+		// Expand the patterns from the list of namespaces and add all
+		// namespaces matching the namespace selector. Afterwards we remove all
+		// excluded namespaces.
+		namespaces, err := resolveNamespaces(ctx, r.Client, namespaceRole.Spec.Namespaces, namespaceRole.Spec.NamespaceSelector)
+		if err != nil {
+			log.Error(err, "Failed to resolve namespaces")
+			return err
 		}
 
-		err = ctrl.SetControllerReference(namespaceRole, clusterRole, r.Scheme)
+		namespaces, err = excludeNamespaces(ctx, r.Client, namespaces, namespaceRole.Spec.ExcludeNamespaces, namespaceRole.Spec.ExcludeNamespaceSelector)
 		if err != nil {
-			return ctrl.Result{}, err
+			log.Error(err, "Failed to exclude namespaces")
+			return err
 		}
 
-		existingClusterRole := &rbacv1.ClusterRole{}
-		err = r.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, existingClusterRole)
-		if err != nil && errors.IsNotFound(err) {
-			if err := r.Create(ctx, clusterRole); err != nil {
-				log.Error(err, "Failed to create ClusterRole", "ClusterRole.Name", clusterRole.Name)
-				return ctrl.Result{}, err
+		// Loop through the list of namespaces and create a Role in each
+		// namespace. Namespaces which do not exist yet are skipped and added to
+		// the list of missing namespaces in the status. The Role is created as
+		// soon as the namespace is created.
+		for _, namespace := range namespaces {
+			ns := &corev1.Namespace{}
+			if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+				if errors.IsNotFound(err) {
+					log.Info("Namespace not found", "Namespace.Name", namespace)
+					missingNamespaces = append(missingNamespaces, namespace)
+					continue
+				}
+
+				log.Error(err, "Failed to get Namespace", "Namespace.Name", namespace)
+				failures = append(failures, newFailure("Namespace", "", namespace, err))
+				errs = append(errs, err)
+				continue
 			}
-		} else if err != nil {
-			log.Error(err, "Failed to get ClusterRole", "ClusterRole.Name", clusterRole.Name)
-			return ctrl.Result{}, err
-		} else {
-			if err := r.Update(ctx, clusterRole); err != nil {
-				log.Error(err, "Failed to update ClusterRole", "ClusterRole.Name", clusterRole.Name)
-				return ctrl.Result{}, err
+
+			existingNamespaces = append(existingNamespaces, namespace)
+
+			// If the NamespaceRole references an existing ClusterRole, we
+			// do not create a Role. The ClusterRole is bound via a
+			// RoleBinding by the NamespaceRoleBindings instead.
+			if namespaceRole.Spec.ClusterRoleRef != "" {
+				continue
 			}
-		}
 
-		processedClusterRoles = append(processedClusterRoles, kobsiov1alpha1.NamespaceRoleStatusRole{
-			Name:      clusterRole.Name,
-			Namespace: clusterRole.Namespace,
-		})
-	} else {
-		// Loop through the list of namespaces and create a Role in each
-		// namespace.
-		for _, namespace := range namespaceRole.Spec.Namespaces {
 			role := &rbacv1.Role{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      namespaceRole.Name,
 					Namespace: namespace,
-					Labels: map[string]string{
-						selectorLabelKeyNR: namespaceRole.Name,
-					},
 				},
-				Rules: namespaceRole.Spec.Rules,
 			}
 
-			err = ctrl.SetControllerReference(namespaceRole, role, r.Scheme)
+			// Apply the overrides for the namespace and expand the variables
+			// in the rules, so that the Roles can have different rules in
+			// different namespaces.
+			namespaceRules, err := applyOverrides(roleRules, namespaceRole.Spec.Overrides, ns)
 			if err != nil {
-				return ctrl.Result{}, err
+				log.Error(err, "Failed to apply overrides", "Namespace.Name", namespace)
+				failures = append(failures, newFailure("Role", role.Namespace, role.Name, err))
+				errs = append(errs, err)
+				continue
 			}
 
-			existingRole := &rbacv1.Role{}
-			err = r.Get(ctx, types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, existingRole)
-			if err != nil && errors.IsNotFound(err) {
-				if err := r.Create(ctx, role); err != nil {
-					log.Error(err, "Failed to create Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
-					return ctrl.Result{}, err
-				}
-			} else if err != nil {
-				log.Error(err, "Failed to get Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
-				return ctrl.Result{}, err
-			} else {
-				if err := r.Update(ctx, role); err != nil {
-					log.Error(err, "Failed to update Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
-					return ctrl.Result{}, err
+			namespaceRules, err = expandRules(namespaceRules, ns)
+			if err != nil {
+				log.Error(err, "Failed to expand rules", "Namespace.Name", namespace)
+				failures = append(failures, newFailure("Role", role.Namespace, role.Name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			err = createOrUpdateWithDrift(ctx, r.Client, r.Recorder, namespaceRole, "Role", role, wasProcessedNR(role.Namespace, role.Name, namespaceRole.Status.Roles), func() string {
+				return hashRules(role.Rules)
+			}, func() error {
+				role.Labels = map[string]string{
+					selectorLabelKeyNR: namespaceRole.Name,
 				}
+				role.Rules = namespaceRules
+
+				return ctrl.SetControllerReference(namespaceRole, role, r.Scheme)
+			})
+			if err != nil {
+				log.Error(err, "Failed to create or update Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
+				failures = append(failures, newFailure("Role", role.Namespace, role.Name, err))
+				errs = append(errs, err)
+				continue
 			}
 
-			processedRoles = append(processedRoles, kobsiov1alpha1.NamespaceRoleStatusRole{
+			processedRoles = append(processedRoles, kobsiov1alpha2.NamespaceRoleStatusRole{
 				Name:      role.Name,
 				Namespace: role.Namespace,
 			})
 		}
 	}
 
+	// For the scope Namespaced, the rules for cluster-scoped resources are added
+	// to a companion ClusterRole, because they are useless in a Role. The rules
+	// for the namespaces resource are narrowed down to the namespaces of the
+	// Roles, so that users can get their namespaces without getting access to
+	// all namespaces.
+	if scope == kobsiov1alpha2.NamespaceRoleScopeNamespaced {
+		clusterRoleRules = narrowNamespaceRules(clusterRoleRules, existingNamespaces)
+	}
+
+	if namespaceRole.Spec.ClusterRoleRef != "" {
+		// We only check if the referenced ClusterRole exists, so that a typo
+		// is visible in the status of the NamespaceRole. The ClusterRole is
+		// managed outside of the operator.
+		if err := r.Get(ctx, types.NamespacedName{Name: namespaceRole.Spec.ClusterRoleRef}, &rbacv1.ClusterRole{}); err != nil {
+			log.Error(err, "Failed to get referenced ClusterRole", "ClusterRole.Name", namespaceRole.Spec.ClusterRoleRef)
+			failures = append(failures, newFailure("ClusterRole", "", namespaceRole.Spec.ClusterRoleRef, err))
+			errs = append(errs, err)
+		}
+	} else if scope == kobsiov1alpha2.NamespaceRoleScopeCluster || len(clusterRoleRules) > 0 {
+		clusterRole := &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespaceRole.Name,
+			},
+		}
+
+		// The ClusterRole has the same name as the NamespaceRole, so that we
+		// must not adopt an existing ClusterRole (e.g. "edit" or "view"), which
+		// was not created by the operator for this NamespaceRole. Otherwise it
+		// would be overwritten and garbage collected with the NamespaceRole.
+		err := createOrUpdateWithDrift(ctx, r.Client, r.Recorder, namespaceRole, "ClusterRole", clusterRole, wasProcessedNR(clusterRole.Namespace, clusterRole.Name, namespaceRole.Status.ClusterRoles), func() string {
+			return hashRules(clusterRole.Rules)
+		}, func() error {
+			if err := checkClusterRoleOwner(clusterRole, namespaceRole); err != nil {
+				return err
+			}
+
+			clusterRole.Labels = map[string]string{
+				selectorLabelKeyNR: namespaceRole.Name,
+			}
+			clusterRole.Rules = clusterRoleRules
+
+			return ctrl.SetControllerReference(namespaceRole, clusterRole, r.Scheme)
+		})
+		if err != nil {
+			log.Error(err, "Failed to create or update ClusterRole", "ClusterRole.Name", clusterRole.Name)
+			failures = append(failures, newFailure("ClusterRole", clusterRole.Namespace, clusterRole.Name, err))
+			errs = append(errs, err)
+		} else {
+			processedClusterRoles = append(processedClusterRoles, kobsiov1alpha2.NamespaceRoleStatusRole{
+				Name:      clusterRole.Name,
+				Namespace: clusterRole.Namespace,
+			})
+		}
+	}
+
 	// Get a list of all existing ClusterRoles and Roles, which were created by
 	// the operator for the NamespaceRole.
 	existingClusterRoles := &rbacv1.ClusterRoleList{}
@@ -159,7 +297,7 @@ func (r *NamespaceRoleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}),
 	}); err != nil {
 		log.Error(err, "Failed to list ClusterRoles")
-		return ctrl.Result{}, err
+		errs = append(errs, err)
 	}
 
 	existingRoles := &rbacv1.RoleList{}
@@ -169,26 +307,29 @@ func (r *NamespaceRoleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}),
 	}); err != nil {
 		log.Error(err, "Failed to list Roles")
-		return ctrl.Result{}, err
+		errs = append(errs, err)
 	}
 
 	// Compare the list of existing ClusterRoles and Roles with the list of
 	// processed ClusterRoles and Roles. If a ClusterRole or Role exists, which
-	// was not processed, we delete it.
+	// was not processed, we delete it. ClusterRoles and Roles which failed are
+	// still desired, so that we keep them.
 	for _, existingClusterRole := range existingClusterRoles.Items {
-		if !wasProcessedNR(existingClusterRole.Namespace, existingClusterRole.Name, processedClusterRoles) {
-			if err := r.Delete(ctx, &existingClusterRole); err != nil {
+		if !wasProcessedNR(existingClusterRole.Namespace, existingClusterRole.Name, processedClusterRoles) && !hasFailed("ClusterRole", existingClusterRole.Namespace, existingClusterRole.Name, failures) {
+			if err := r.Delete(ctx, &existingClusterRole); err != nil && !errors.IsNotFound(err) {
 				log.Error(err, "Failed to delete ClusterRole", "ClusterRole.Namespace", existingClusterRole.Namespace, "ClusterRole.Name", existingClusterRole.Name)
-				return ctrl.Result{}, err
+				failures = append(failures, newFailure("ClusterRole", existingClusterRole.Namespace, existingClusterRole.Name, err))
+				errs = append(errs, err)
 			}
 		}
 	}
 
 	for _, existingRole := range existingRoles.Items {
-		if !wasProcessedNR(existingRole.Namespace, existingRole.Name, processedRoles) {
-			if err := r.Delete(ctx, &existingRole); err != nil {
+		if !wasProcessedNR(existingRole.Namespace, existingRole.Name, processedRoles) && !hasFailed("Role", existingRole.Namespace, existingRole.Name, failures) {
+			if err := r.Delete(ctx, &existingRole); err != nil && !errors.IsNotFound(err) {
 				log.Error(err, "Failed to delete Role", "Role.Namespace", existingRole.Namespace, "Role.Name", existingRole.Name)
-				return ctrl.Result{}, err
+				failures = append(failures, newFailure("Role", existingRole.Namespace, existingRole.Name, err))
+				errs = append(errs, err)
 			}
 		}
 	}
@@ -196,17 +337,47 @@ func (r *NamespaceRoleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	namespaceRole.Status.Selector = fmt.Sprintf("%s=%s", selectorLabelKeyNR, namespaceRole.Name)
 	namespaceRole.Status.ClusterRoles = processedClusterRoles
 	namespaceRole.Status.Roles = processedRoles
+	namespaceRole.Status.ClusterRoleRef = namespaceRole.Spec.ClusterRoleRef
+	namespaceRole.Status.Namespaces = existingNamespaces
+	namespaceRole.Status.MissingNamespaces = missingNamespaces
+	namespaceRole.Status.Failures = failures
 
-	err = r.Status().Update(ctx, namespaceRole)
-	if err != nil {
-		log.Error(err, "Failed to update status")
-		return ctrl.Result{}, err
+	if len(missingNamespaces) > 0 {
+		meta.SetStatusCondition(&namespaceRole.Status.Conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeNamespacesMissing,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: namespaceRole.Generation,
+			Reason:             "NamespacesNotFound",
+			Message:            fmt.Sprintf("Namespaces not found: %s", strings.Join(missingNamespaces, ", ")),
+		})
+	} else {
+		meta.SetStatusCondition(&namespaceRole.Status.Conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeNamespacesMissing,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: namespaceRole.Generation,
+			Reason:             "NamespacesFound",
+			Message:            "All namespaces exist",
+		})
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// checkClusterRoleOwner returns an error when the provided ClusterRole already
+// exists, but was not created by the operator for the provided NamespaceRole.
+func checkClusterRoleOwner(clusterRole *rbacv1.ClusterRole, namespaceRole *kobsiov1alpha2.NamespaceRole) error {
+	if clusterRole.CreationTimestamp.Time.IsZero() {
+		return nil
+	}
+
+	if clusterRole.Labels[selectorLabelKeyNR] != namespaceRole.Name || !metav1.IsControlledBy(clusterRole, namespaceRole) {
+		return fmt.Errorf("ClusterRole %q already exists and is not managed by the NamespaceRole", clusterRole.Name)
 	}
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
-func wasProcessedNR(namespace, name string, processedRoles []kobsiov1alpha1.NamespaceRoleStatusRole) bool {
+func wasProcessedNR(namespace, name string, processedRoles []kobsiov1alpha2.NamespaceRoleStatusRole) bool {
 	for _, role := range processedRoles {
 		if role.Namespace == namespace && role.Name == name {
 			return true
@@ -216,16 +387,73 @@ func wasProcessedNR(namespace, name string, processedRoles []kobsiov1alpha1.Name
 	return false
 }
 
-// SetupWithManager sets up the controller with the Manager. In the event filter
+// dependsOnNamespace returns true when the Roles of a NamespaceRole can change
+// when the provided namespace is created, relabeled or deleted. This is the case
+// when the namespace is part of the Namespaces list or when the NamespaceRole
+// uses namespace selectors or patterns.
+func dependsOnNamespace(spec kobsiov1alpha2.NamespaceRoleSpec, namespace string) bool {
+	if spec.GetScope() == kobsiov1alpha2.NamespaceRoleScopeCluster {
+		return false
+	}
+
+	return contains(spec.Namespaces, namespace) || spec.NamespaceSelector != nil || spec.ExcludeNamespaceSelector != nil || hasNamespacePattern(spec.Namespaces) || hasNamespacePattern(spec.ExcludeNamespaces)
+}
+
+// findNamespaceRolesForNamespace returns a reconcile request for all
+// NamespaceRoles which depend on the existing namespaces, so that the Roles are
+// created or deleted when a namespace is created, relabeled or deleted.
+func (r *NamespaceRoleReconciler) findNamespaceRolesForNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	namespaceRoles := &kobsiov1alpha2.NamespaceRoleList{}
+	if err := r.List(ctx, namespaceRoles); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NamespaceRoles", "Namespace.Name", namespace.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, namespaceRole := range namespaceRoles.Items {
+		if dependsOnNamespace(namespaceRole.Spec, namespace.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceRole.Name}})
+		}
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager. For NamespaceRoles
 // we ignore updates to CR status in which case metadata.Generation does not
-// change.
+// change. For Namespaces we only care about created and deleted namespaces and
+// about changed labels. Changes to NamespaceRoles and ClusterRoles are also
+// mapped to all NamespaceRoles including them. Changes to the generated
+// ClusterRoles and Roles are mapped to their NamespaceRole, so that changes
+// made outside of the operator are reverted.
 func (r *NamespaceRoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kobsiov1alpha2.NamespaceRole{}, includesIndexKey, indexIncludes); err != nil {
+		return err
+	}
+
+	generationChangedPredicate := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kobsiov1alpha1.NamespaceRole{}).
-		WithEventFilter(predicate.Funcs{
-			UpdateFunc: func(e event.UpdateEvent) bool {
-				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
-			},
-		}).
+		For(&kobsiov1alpha2.NamespaceRole{}, builder.WithPredicates(generationChangedPredicate)).
+		Watches(
+			&kobsiov1alpha2.NamespaceRole{},
+			handler.EnqueueRequestsFromMapFunc(r.findNamespaceRolesForNamespaceRole),
+			builder.WithPredicates(generationChangedPredicate),
+		).
+		Watches(
+			&rbacv1.ClusterRole{},
+			handler.EnqueueRequestsFromMapFunc(r.findNamespaceRolesForClusterRole),
+		).
+		Owns(&rbacv1.ClusterRole{}, builder.WithPredicates(hasLabelPredicate(selectorLabelKeyNR))).
+		Owns(&rbacv1.Role{}, builder.WithPredicates(hasLabelPredicate(selectorLabelKeyNR))).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findNamespaceRolesForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		Complete(r)
 }
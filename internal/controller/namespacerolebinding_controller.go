@@ -3,35 +3,57 @@ package controller
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
 
-	kobsiov1alpha1 "github.com/kobsio/namespacerole-operator/api/v1alpha1"
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
 
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	selectorLabelKeyNRB = "kobs.io/namespacerolebinding"
+	selectorLabelKeyNRB    = "kobs.io/namespacerolebinding"
+	selectorLabelKeyNRBUID = "kobs.io/namespacerolebinding-uid"
+
+	// roleRefIndexKey is the name of the field index for the NamespaceRoles
+	// referenced by a NamespaceRoleBinding via the roleRef or roleRefs field.
+	roleRefIndexKey = ".spec.roleRef.name"
 )
 
 // NamespaceRoleBindingReconciler reconciles a NamespaceRoleBinding object
 type NamespaceRoleBindingReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// BreakGlassMaxTTL is the maximum duration of an emergency access. If it
+	// is not set, the DefaultBreakGlassMaxTTL is used.
+	BreakGlassMaxTTL time.Duration
 }
 
 // +kubebuilder:rbac:groups=kobs.io,resources=namespacerolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kobs.io,resources=namespacerolebindings/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kobs.io,resources=namespacerolebindings/finalizers,verbs=update
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state. For more
@@ -41,7 +63,7 @@ func (r *NamespaceRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl
 	log := log.FromContext(ctx)
 	log.Info("Reconcile NamespaceRoleBinding")
 
-	namespaceRoleBinding := &kobsiov1alpha1.NamespaceRoleBinding{}
+	namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
 	err := r.Get(ctx, req.NamespacedName, namespaceRoleBinding)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -56,162 +78,718 @@ func (r *NamespaceRoleBindingReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, err
 	}
 
-	namespaceRole := &kobsiov1alpha1.NamespaceRole{}
-	if err := r.Get(ctx, types.NamespacedName{Name: namespaceRoleBinding.Spec.RoleRef.Name}, namespaceRole); err != nil {
-		log.Error(err, "Failed to get NamespaceRole", "NamespaceRole.Name", namespaceRoleBinding.Spec.RoleRef.Name)
+	// Check if the NamespaceRoleBinding is inside of its validity window and
+	// its schedule. If this is the case we reconcile the ClusterRoleBindings /
+	// RoleBindings, otherwise we remove them. The request is requeued for the
+	// next transition, so that the access is granted and revoked on time
+	// without a change to the NamespaceRoleBinding. If the schedule is invalid
+	// we also remove the bindings, so that an invalid schedule never grants
+	// access. For an emergency access the validity window is limited to the
+	// maximum break-glass duration.
+	now := time.Now()
+	spec, err := r.reconcileBreakGlass(ctx, namespaceRoleBinding, now)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
+	v, validityErr := getValidity(spec, now)
+	if validityErr != nil {
+		log.Error(validityErr, "Failed to evaluate schedule")
+		v = validity{Active: false, Reason: "InvalidSchedule", Message: validityErr.Error()}
+	}
 
-	var processedClusterRoleBindings []kobsiov1alpha1.NamespaceRoleStatusRoleBinding
-	var processedRoleBindings []kobsiov1alpha1.NamespaceRoleStatusRoleBinding
+	var reconcileErr error
+	var waitingFor string
+	if v.Active {
+		reconcileErr = r.reconcileRoleBindings(ctx, namespaceRoleBinding)
+	} else {
+		reconcileErr = r.revokeRoleBindings(ctx, namespaceRoleBinding)
+		if !v.NextTransition.IsZero() {
+			waitingFor = v.Message
+		}
+	}
+	reconcileErr = utilerrors.NewAggregate([]error{validityErr, reconcileErr})
 
-	for _, clusterRole := range namespaceRole.Status.ClusterRoles {
-		clusterRoleBinding := &rbacv1.ClusterRoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespaceRoleBinding.Name,
-				Labels: map[string]string{
-					selectorLabelKeyNRB: namespaceRole.Name,
-				},
-			},
-			RoleRef: rbacv1.RoleRef{
+	namespaceRoleBinding.Status.NextTransitionTime = nil
+	if !v.NextTransition.IsZero() {
+		namespaceRoleBinding.Status.NextTransitionTime = &metav1.Time{Time: v.NextTransition}
+	}
+
+	// Set the conditions based on the result, so that failures are not only
+	// visible in the logs of the operator.
+	namespaceRoleBinding.Status.ObservedGeneration = namespaceRoleBinding.Generation
+	setConditions(&namespaceRoleBinding.Status.Conditions, namespaceRoleBinding.Generation, reconcileErr, waitingFor)
+	setActiveCondition(&namespaceRoleBinding.Status.Conditions, namespaceRoleBinding.Generation, v)
+	setBreakGlassCondition(&namespaceRoleBinding.Status.Conditions, namespaceRoleBinding.Generation, namespaceRoleBinding)
+
+	err = r.Status().Update(ctx, namespaceRoleBinding)
+	if err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	var result ctrl.Result
+	if !v.NextTransition.IsZero() {
+		result.RequeueAfter = v.NextTransition.Sub(now)
+	}
+
+	return result, reconcileErr
+}
+
+// revokeRoleBindings deletes all ClusterRoleBindings / RoleBindings of the
+// provided NamespaceRoleBinding. It is used when the NamespaceRoleBinding is
+// outside of its validity window.
+func (r *NamespaceRoleBindingReconciler) revokeRoleBindings(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) error {
+	remainingClusterRoleBindings, remainingRoleBindings, failures, errs := r.deleteStaleRoleBindings(ctx, namespaceRoleBinding, nil, nil, nil)
+
+	namespaceRoleBinding.Status.Selector = labels.SelectorFromSet(getBindingLabels(namespaceRoleBinding)).String()
+	namespaceRoleBinding.Status.RoleRefs = nil
+	namespaceRoleBinding.Status.ClusterRoleBindings = remainingClusterRoleBindings
+	namespaceRoleBinding.Status.RoleBindings = remainingRoleBindings
+	namespaceRoleBinding.Status.Failures = failures
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteStaleRoleBindings deletes all ClusterRoleBindings / RoleBindings of the
+// provided NamespaceRoleBinding, which were not processed and did not fail in
+// the current reconciliation. These are all objects with the labels of the
+// NamespaceRoleBinding and all objects from the status of the last
+// reconciliation, so that objects created by older versions of the operator
+// with other labels are also removed. Objects from the status are only deleted
+// when they were created by the operator (see isGeneratedBinding), because the
+// status can be written by other clients. The objects which couldn't be deleted
+// are returned together with the failures.
+func (r *NamespaceRoleBindingReconciler) deleteStaleRoleBindings(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, processedClusterRoleBindings, processedRoleBindings []kobsiov1alpha2.NamespaceRoleStatusRoleBinding, failures []kobsiov1alpha2.NamespaceRoleStatusFailure) ([]kobsiov1alpha2.NamespaceRoleStatusRoleBinding, []kobsiov1alpha2.NamespaceRoleStatusRoleBinding, []kobsiov1alpha2.NamespaceRoleStatusFailure, []error) {
+	log := log.FromContext(ctx)
+
+	var remainingClusterRoleBindings []kobsiov1alpha2.NamespaceRoleStatusRoleBinding
+	var remainingRoleBindings []kobsiov1alpha2.NamespaceRoleStatusRoleBinding
+	var errs []error
+
+	// We only use the UID for the label selector, so that ClusterRoleBindings
+	// and RoleBindings of a deleted NamespaceRoleBinding with the same name
+	// are not touched. They are removed by the garbage collector.
+	selector := labels.SelectorFromSet(map[string]string{
+		selectorLabelKeyNRBUID: string(namespaceRoleBinding.UID),
+	})
+
+	existingClusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	if err := r.List(ctx, existingClusterRoleBindings, &client.ListOptions{LabelSelector: selector}); err != nil {
+		log.Error(err, "Failed to list ClusterRoleBindings")
+		errs = append(errs, err)
+	}
+
+	for _, clusterRoleBinding := range namespaceRoleBinding.Status.ClusterRoleBindings {
+		if containsClusterRoleBinding(existingClusterRoleBindings.Items, clusterRoleBinding.Name) {
+			continue
+		}
+
+		existingClusterRoleBinding := rbacv1.ClusterRoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: clusterRoleBinding.Name}, &existingClusterRoleBinding); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "Failed to get ClusterRoleBinding", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
+				remainingClusterRoleBindings = append(remainingClusterRoleBindings, clusterRoleBinding)
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if !isGeneratedBinding(&existingClusterRoleBinding, namespaceRoleBinding) {
+			log.Info("Ignore ClusterRoleBinding from the status, which was not created by the operator", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
+			continue
+		}
+
+		existingClusterRoleBindings.Items = append(existingClusterRoleBindings.Items, existingClusterRoleBinding)
+	}
+
+	existingRoleBindings := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, existingRoleBindings, &client.ListOptions{LabelSelector: selector}); err != nil {
+		log.Error(err, "Failed to list RoleBindings")
+		errs = append(errs, err)
+	}
+
+	for _, roleBinding := range namespaceRoleBinding.Status.RoleBindings {
+		if containsRoleBinding(existingRoleBindings.Items, roleBinding.Namespace, roleBinding.Name) {
+			continue
+		}
+
+		existingRoleBinding := rbacv1.RoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &existingRoleBinding); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "Failed to get RoleBinding", "RoleBinding.Namespace", roleBinding.Namespace, "RoleBinding.Name", roleBinding.Name)
+				remainingRoleBindings = append(remainingRoleBindings, roleBinding)
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if !isGeneratedBinding(&existingRoleBinding, namespaceRoleBinding) {
+			log.Info("Ignore RoleBinding from the status, which was not created by the operator", "RoleBinding.Namespace", roleBinding.Namespace, "RoleBinding.Name", roleBinding.Name)
+			continue
+		}
+
+		existingRoleBindings.Items = append(existingRoleBindings.Items, existingRoleBinding)
+	}
+
+	// Compare the list of existing ClusterRoleBindings and RoleBindings with
+	// the list of processed ClusterRoleBindings and RoleBindings. If a
+	// ClusterRoleBinding or RoleBinding exists, which was not processed, we
+	// delete it. ClusterRoleBindings and RoleBindings which failed are still
+	// desired, so that we keep them. ClusterRoleBindings and RoleBindings
+	// which are controlled by another NamespaceRoleBinding are also kept,
+	// because they are not stale.
+	for _, existingClusterRoleBinding := range existingClusterRoleBindings.Items {
+		if wasProcessedNRB(existingClusterRoleBinding.Namespace, existingClusterRoleBinding.Name, processedClusterRoleBindings) || hasFailed("ClusterRoleBinding", existingClusterRoleBinding.Namespace, existingClusterRoleBinding.Name, failures) || isControlledByOtherNamespaceRoleBinding(&existingClusterRoleBinding, namespaceRoleBinding) {
+			continue
+		}
+
+		if err := r.Delete(ctx, &existingClusterRoleBinding); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete ClusterRoleBinding", "ClusterRoleBinding.Name", existingClusterRoleBinding.Name)
+			remainingClusterRoleBindings = append(remainingClusterRoleBindings, kobsiov1alpha2.NamespaceRoleStatusRoleBinding{Name: existingClusterRoleBinding.Name})
+			failures = append(failures, newFailure("ClusterRoleBinding", existingClusterRoleBinding.Namespace, existingClusterRoleBinding.Name, err))
+			errs = append(errs, err)
+		}
+	}
+
+	for _, existingRoleBinding := range existingRoleBindings.Items {
+		if wasProcessedNRB(existingRoleBinding.Namespace, existingRoleBinding.Name, processedRoleBindings) || hasFailed("RoleBinding", existingRoleBinding.Namespace, existingRoleBinding.Name, failures) || isControlledByOtherNamespaceRoleBinding(&existingRoleBinding, namespaceRoleBinding) {
+			continue
+		}
+
+		if err := r.Delete(ctx, &existingRoleBinding); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete RoleBinding", "RoleBinding.Namespace", existingRoleBinding.Namespace, "RoleBinding.Name", existingRoleBinding.Name)
+			remainingRoleBindings = append(remainingRoleBindings, kobsiov1alpha2.NamespaceRoleStatusRoleBinding{Name: existingRoleBinding.Name, Namespace: existingRoleBinding.Namespace})
+			failures = append(failures, newFailure("RoleBinding", existingRoleBinding.Namespace, existingRoleBinding.Name, err))
+			errs = append(errs, err)
+		}
+	}
+
+	return remainingClusterRoleBindings, remainingRoleBindings, failures, errs
+}
+
+// reconcileRoleBindings creates, updates and deletes the ClusterRoleBindings /
+// RoleBindings for the provided NamespaceRoleBinding and sets the corresponding
+// status fields. The status is not written to the API server, this is done by
+// the caller.
+//
+// A failure for one ClusterRoleBinding / RoleBinding doesn't stop the
+// reconciliation of the others. All errors are aggregated and returned, so that
+// the request is requeued with a backoff. Since unchanged ClusterRoleBindings /
+// RoleBindings are not updated, only the failed objects are written again in
+// the next run.
+func (r *NamespaceRoleBindingReconciler) reconcileRoleBindings(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) error {
+	log := log.FromContext(ctx)
+
+	var processedRoleRefs []kobsiov1alpha2.NamespaceRoleBindingStatusRoleRef
+	var processedClusterRoleBindings []kobsiov1alpha2.NamespaceRoleStatusRoleBinding
+	var processedRoleBindings []kobsiov1alpha2.NamespaceRoleStatusRoleBinding
+	var failures []kobsiov1alpha2.NamespaceRoleStatusFailure
+	var errs []error
+
+	// If the NamespaceRoleBinding should only bind the roles in some
+	// namespaces, we resolve the namespaces once, so that we can use them to
+	// narrow down the bindings for all referenced roles.
+	var namespaces []string
+	if namespaceRoleBinding.Spec.HasNamespaces() {
+		var err error
+		namespaces, err = r.resolveNamespaces(ctx, namespaceRoleBinding)
+		if err != nil {
+			log.Error(err, "Failed to resolve namespaces")
+			return err
+		}
+	}
+
+	// Loop through all roles referenced by the NamespaceRoleBinding and create
+	// the ClusterRoleBindings / RoleBindings for each of them. A NamespaceRole
+	// is bound via the ClusterRoles and Roles from its status, a ClusterRole is
+	// bound via a ClusterRoleBinding.
+	for _, roleRef := range namespaceRoleBinding.Spec.GetRoleRefs() {
+		var clusterRoleRefs []rbacv1.RoleRef
+		var roleRefs []namespacedRoleRef
+		bindsAllNamespaces := true
+
+		if roleRef.Kind == kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole {
+			clusterRoleRefs = append(clusterRoleRefs, rbacv1.RoleRef{
 				APIGroup: "rbac.authorization.k8s.io",
 				Kind:     "ClusterRole",
-				Name:     clusterRole.Name,
-			},
-			Subjects: namespaceRoleBinding.Spec.Subjects,
+				Name:     roleRef.Name,
+			})
+		} else {
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			if err := r.Get(ctx, types.NamespacedName{Name: roleRef.Name}, namespaceRole); err != nil {
+				log.Error(err, "Failed to get NamespaceRole", "NamespaceRole.Name", roleRef.Name)
+				failures = append(failures, newFailure("NamespaceRole", "", roleRef.Name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			clusterRoleRefs, roleRefs = getNamespaceRoleRefs(namespaceRole)
+			bindsAllNamespaces = namespaceRole.Spec.GetScope() == kobsiov1alpha2.NamespaceRoleScopeCluster
 		}
 
-		err = ctrl.SetControllerReference(namespaceRole, clusterRoleBinding, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
+		if namespaceRoleBinding.Spec.HasNamespaces() {
+			clusterRoleRefs, roleRefs = narrowRoleRefs(clusterRoleRefs, roleRefs, namespaces, bindsAllNamespaces)
+		}
+
+		name := getBindingName(namespaceRoleBinding, roleRef)
+		processedRoleRef := kobsiov1alpha2.NamespaceRoleBindingStatusRoleRef{
+			Kind: roleRef.Kind,
+			Name: roleRef.Name,
 		}
 
-		existingClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-		err = r.Get(ctx, types.NamespacedName{Name: clusterRoleBinding.Name}, existingClusterRoleBinding)
-		if err != nil && errors.IsNotFound(err) {
-			if err := r.Create(ctx, clusterRoleBinding); err != nil {
-				log.Error(err, "Failed to create ClusterRoleBinding", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
-				return ctrl.Result{}, err
+		// Variables in the subjects can only be expanded for RoleBindings, so
+		// that subjects with variables are not added to ClusterRoleBindings. If
+		// no subjects are left, we do not create the ClusterRoleBindings. When
+		// the role is only bound via ClusterRoleBindings, the subjects with
+		// variables would not get any access, so that we report it as failure
+		// instead of silently ignoring them.
+		clusterRoleBindingSubjects := withoutTemplatedSubjects(namespaceRoleBinding.Spec.Subjects)
+		if bindsAllNamespaces && len(clusterRoleRefs) > 0 && len(clusterRoleBindingSubjects) != len(namespaceRoleBinding.Spec.Subjects) {
+			err := fmt.Errorf("subjects with variables can not be bound to %s %q via a ClusterRoleBinding, namespaces or namespaceSelector is required", roleRef.Kind, roleRef.Name)
+			log.Error(err, "Failed to bind subjects with variables", "Kind", roleRef.Kind, "Name", roleRef.Name)
+			failures = append(failures, newFailure("NamespaceRoleBinding", "", namespaceRoleBinding.Name, err))
+			errs = append(errs, err)
+		}
+		if len(clusterRoleBindingSubjects) == 0 {
+			clusterRoleRefs = nil
+		}
+
+		for _, clusterRoleRef := range clusterRoleRefs {
+			// The roleRef of a ClusterRoleBinding can not be changed, so that
+			// we have to use another name when an existing ClusterRoleBinding
+			// references another role. The old ClusterRoleBinding is deleted
+			// after the new one was created.
+			clusterRoleBindingName, err := r.resolveClusterRoleBindingName(ctx, name, clusterRoleRef)
+			if err != nil {
+				log.Error(err, "Failed to get ClusterRoleBinding", "ClusterRoleBinding.Name", name)
+				failures = append(failures, newFailure("ClusterRoleBinding", "", name, err))
+				errs = append(errs, err)
+				continue
 			}
-		} else if err != nil {
-			log.Error(err, "Failed to get ClusterRoleBinding", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
-			return ctrl.Result{}, err
-		} else {
-			if err := r.Update(ctx, clusterRoleBinding); err != nil {
-				log.Error(err, "Failed to update ClusterRoleBinding", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
-				return ctrl.Result{}, err
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: clusterRoleBindingName,
+				},
 			}
+
+			err = createOrUpdateWithDrift(ctx, r.Client, r.Recorder, namespaceRoleBinding, "ClusterRoleBinding", clusterRoleBinding, wasProcessedNRB(clusterRoleBinding.Namespace, clusterRoleBinding.Name, namespaceRoleBinding.Status.ClusterRoleBindings), func() string {
+				return hashBinding(clusterRoleBinding.RoleRef, clusterRoleBinding.Subjects)
+			}, func() error {
+				if err := checkBindingOwner("ClusterRoleBinding", clusterRoleBinding, namespaceRoleBinding); err != nil {
+					return err
+				}
+
+				clusterRoleBinding.Labels = getBindingLabels(namespaceRoleBinding)
+				clusterRoleBinding.RoleRef = clusterRoleRef
+				clusterRoleBinding.Subjects = clusterRoleBindingSubjects
+
+				return setBindingOwner(namespaceRoleBinding, clusterRoleBinding, r.Scheme)
+			})
+			if err != nil {
+				log.Error(err, "Failed to create or update ClusterRoleBinding", "ClusterRoleBinding.Name", clusterRoleBinding.Name)
+				failures = append(failures, newFailure("ClusterRoleBinding", clusterRoleBinding.Namespace, clusterRoleBinding.Name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			processedClusterRoleBinding := kobsiov1alpha2.NamespaceRoleStatusRoleBinding{
+				Name:      clusterRoleBinding.Name,
+				Namespace: clusterRoleBinding.Namespace,
+			}
+			processedClusterRoleBindings = append(processedClusterRoleBindings, processedClusterRoleBinding)
+			processedRoleRef.ClusterRoleBindings = append(processedRoleRef.ClusterRoleBindings, processedClusterRoleBinding)
+		}
+
+		for _, namespacedRef := range roleRefs {
+			// The roleRef of a RoleBinding can not be changed, e.g. when a
+			// NamespaceRole switches between all and some namespaces, so that
+			// we have to use another name when an existing RoleBinding
+			// references another role. The old RoleBinding is deleted after
+			// the new one was created.
+			roleBindingName, err := r.resolveRoleBindingName(ctx, namespacedRef.Namespace, name, namespacedRef.RoleRef)
+			if err != nil {
+				log.Error(err, "Failed to get RoleBinding", "RoleBinding.Namespace", namespacedRef.Namespace, "RoleBinding.Name", name)
+				failures = append(failures, newFailure("RoleBinding", namespacedRef.Namespace, name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      roleBindingName,
+					Namespace: namespacedRef.Namespace,
+				},
+			}
+
+			// Expand the variables in the subjects for the namespace of the
+			// RoleBinding, so that e.g. the ServiceAccount of each namespace
+			// is bound in its namespace.
+			subjects, err := r.expandSubjects(ctx, namespaceRoleBinding.Spec.Subjects, namespacedRef.Namespace)
+			if err != nil {
+				log.Error(err, "Failed to expand subjects", "RoleBinding.Namespace", roleBinding.Namespace, "RoleBinding.Name", roleBinding.Name)
+				failures = append(failures, newFailure("RoleBinding", roleBinding.Namespace, roleBinding.Name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			err = createOrUpdateWithDrift(ctx, r.Client, r.Recorder, namespaceRoleBinding, "RoleBinding", roleBinding, wasProcessedNRB(roleBinding.Namespace, roleBinding.Name, namespaceRoleBinding.Status.RoleBindings), func() string {
+				return hashBinding(roleBinding.RoleRef, roleBinding.Subjects)
+			}, func() error {
+				if err := checkBindingOwner("RoleBinding", roleBinding, namespaceRoleBinding); err != nil {
+					return err
+				}
+
+				roleBinding.Labels = getBindingLabels(namespaceRoleBinding)
+				roleBinding.RoleRef = namespacedRef.RoleRef
+				roleBinding.Subjects = subjects
+
+				return setBindingOwner(namespaceRoleBinding, roleBinding, r.Scheme)
+			})
+			if err != nil {
+				log.Error(err, "Failed to create or update RoleBinding", "RoleBinding.Namespace", roleBinding.Namespace, "RoleBinding.Name", roleBinding.Name)
+				failures = append(failures, newFailure("RoleBinding", roleBinding.Namespace, roleBinding.Name, err))
+				errs = append(errs, err)
+				continue
+			}
+
+			processedRoleBinding := kobsiov1alpha2.NamespaceRoleStatusRoleBinding{
+				Name:      roleBinding.Name,
+				Namespace: roleBinding.Namespace,
+			}
+			processedRoleBindings = append(processedRoleBindings, processedRoleBinding)
+			processedRoleRef.RoleBindings = append(processedRoleRef.RoleBindings, processedRoleBinding)
 		}
 
-		processedClusterRoleBindings = append(processedClusterRoleBindings, kobsiov1alpha1.NamespaceRoleStatusRoleBinding{
-			Name:      clusterRoleBinding.Name,
-			Namespace: clusterRoleBinding.Namespace,
+		processedRoleRefs = append(processedRoleRefs, processedRoleRef)
+	}
+
+	// Delete all ClusterRoleBindings and RoleBindings of the
+	// NamespaceRoleBinding, which are not desired anymore, e.g. because the
+	// roleRef was changed or a namespace was removed from the NamespaceRole.
+	// ClusterRoleBindings and RoleBindings which couldn't be deleted are kept
+	// in the status, so that we try to delete them again in the next run.
+	remainingClusterRoleBindings, remainingRoleBindings, failures, deleteErrs := r.deleteStaleRoleBindings(ctx, namespaceRoleBinding, processedClusterRoleBindings, processedRoleBindings, failures)
+	errs = append(errs, deleteErrs...)
+
+	namespaceRoleBinding.Status.Selector = labels.SelectorFromSet(getBindingLabels(namespaceRoleBinding)).String()
+	namespaceRoleBinding.Status.RoleRefs = processedRoleRefs
+	namespaceRoleBinding.Status.ClusterRoleBindings = append(processedClusterRoleBindings, remainingClusterRoleBindings...)
+	namespaceRoleBinding.Status.RoleBindings = append(processedRoleBindings, remainingRoleBindings...)
+	namespaceRoleBinding.Status.Failures = failures
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// getNamespaceRoleRefs returns the roles, which should be bound for the
+// provided NamespaceRole. These are the ClusterRoles and Roles created for the
+// NamespaceRole or the ClusterRole referenced by the NamespaceRole. A
+// referenced ClusterRole is bound via a ClusterRoleBinding for the scope
+// Cluster and via RoleBindings in all namespaces of the NamespaceRole
+// otherwise.
+func getNamespaceRoleRefs(namespaceRole *kobsiov1alpha2.NamespaceRole) ([]rbacv1.RoleRef, []namespacedRoleRef) {
+	var clusterRoleRefs []rbacv1.RoleRef
+	var roleRefs []namespacedRoleRef
+
+	for _, clusterRole := range namespaceRole.Status.ClusterRoles {
+		clusterRoleRefs = append(clusterRoleRefs, rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     clusterRole.Name,
 		})
 	}
 
 	for _, role := range namespaceRole.Status.Roles {
-		roleBinding := &rbacv1.RoleBinding{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      namespaceRoleBinding.Name,
-				Namespace: role.Namespace,
-				Labels: map[string]string{
-					selectorLabelKeyNRB: namespaceRole.Name,
-				},
-			},
+		roleRefs = append(roleRefs, namespacedRoleRef{
+			Namespace: role.Namespace,
 			RoleRef: rbacv1.RoleRef{
 				APIGroup: "rbac.authorization.k8s.io",
 				Kind:     "Role",
 				Name:     role.Name,
 			},
-			Subjects: namespaceRoleBinding.Spec.Subjects,
-		}
+		})
+	}
 
-		err = ctrl.SetControllerReference(namespaceRole, roleBinding, r.Scheme)
-		if err != nil {
-			return ctrl.Result{}, err
+	if namespaceRole.Status.ClusterRoleRef != "" {
+		clusterRoleRef := rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     namespaceRole.Status.ClusterRoleRef,
 		}
 
-		existingRoleBinding := &rbacv1.RoleBinding{}
-		err = r.Get(ctx, types.NamespacedName{Namespace: role.Namespace, Name: roleBinding.Name}, existingRoleBinding)
-		if err != nil && errors.IsNotFound(err) {
-			if err := r.Create(ctx, roleBinding); err != nil {
-				log.Error(err, "Failed to create RoleBinding", "RoleBinding.Name", roleBinding.Name)
-				return ctrl.Result{}, err
-			}
-		} else if err != nil {
-			log.Error(err, "Failed to get RoleBinding", "RoleBinding.Name", roleBinding.Name)
-			return ctrl.Result{}, err
+		if namespaceRole.Spec.GetScope() == kobsiov1alpha2.NamespaceRoleScopeCluster {
+			clusterRoleRefs = append(clusterRoleRefs, clusterRoleRef)
 		} else {
-			if err := r.Update(ctx, roleBinding); err != nil {
-				log.Error(err, "Failed to update RoleBinding", "RoleBinding.Name", roleBinding.Name)
-				return ctrl.Result{}, err
+			for _, namespace := range namespaceRole.Status.Namespaces {
+				roleRefs = append(roleRefs, namespacedRoleRef{
+					Namespace: namespace,
+					RoleRef:   clusterRoleRef,
+				})
 			}
 		}
+	}
 
-		processedRoleBindings = append(processedRoleBindings, kobsiov1alpha1.NamespaceRoleStatusRoleBinding{
-			Name:      roleBinding.Name,
-			Namespace: roleBinding.Namespace,
-		})
+	return clusterRoleRefs, roleRefs
+}
+
+// narrowRoleRefs removes all roles in namespaces, which are not part of the
+// provided list of namespaces. If the ClusterRoles grant access to all
+// namespaces (e.g. a plain ClusterRole or a NamespaceRole with the scope
+// Cluster), they are bound via RoleBindings in the provided namespaces instead.
+// Otherwise the ClusterRoles are the companion ClusterRoles with the rules for
+// cluster-scoped resources of a NamespaceRole. They are removed, because they
+// grant access to all namespaces of the NamespaceRole and to cluster-scoped
+// resources, which can not be narrowed down to the provided namespaces.
+func narrowRoleRefs(clusterRoleRefs []rbacv1.RoleRef, roleRefs []namespacedRoleRef, namespaces []string, bindsAllNamespaces bool) ([]rbacv1.RoleRef, []namespacedRoleRef) {
+	var narrowedRoleRefs []namespacedRoleRef
+
+	for _, roleRef := range roleRefs {
+		if contains(namespaces, roleRef.Namespace) {
+			narrowedRoleRefs = append(narrowedRoleRefs, roleRef)
+		}
 	}
 
-	// Get a list of all existing ClusterRoleBindings and RoleBindings, which were
-	// created by the operator for the NamespaceRoleBinding.
-	existingClusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
-	if err := r.List(ctx, existingClusterRoleBindings, &client.ListOptions{
-		LabelSelector: labels.SelectorFromSet(map[string]string{
-			selectorLabelKeyNR: namespaceRole.Name,
-		}),
-	}); err != nil {
-		log.Error(err, "Failed to list ClusterRoleBindings")
-		return ctrl.Result{}, err
+	if !bindsAllNamespaces {
+		return nil, narrowedRoleRefs
 	}
 
-	existingRoleBindings := &rbacv1.RoleBindingList{}
-	if err := r.List(ctx, existingRoleBindings, &client.ListOptions{
-		LabelSelector: labels.SelectorFromSet(map[string]string{
-			selectorLabelKeyNR: namespaceRole.Name,
-		}),
-	}); err != nil {
-		log.Error(err, "Failed to list RoleBindings")
-		return ctrl.Result{}, err
+	for _, clusterRoleRef := range clusterRoleRefs {
+		for _, namespace := range namespaces {
+			narrowedRoleRefs = append(narrowedRoleRefs, namespacedRoleRef{
+				Namespace: namespace,
+				RoleRef:   clusterRoleRef,
+			})
+		}
 	}
 
-	// Compare the list of existing ClusterRoleBindings and RoleBindings with the
-	// list of processed ClusterRoleBindings and RoleBindings. If a
-	// ClusterRoleBinding or RoleBinding exists, which was not processed, we
-	// delete it.
-	for _, existingClusterRoleBinding := range existingClusterRoleBindings.Items {
-		if !wasProcessedNRB(existingClusterRoleBinding.Namespace, existingClusterRoleBinding.Name, processedClusterRoleBindings) {
-			if err := r.Delete(ctx, &existingClusterRoleBinding); err != nil {
-				log.Error(err, "Failed to delete ClusterRoleBinding", "ClusterRole.Namespace", existingClusterRoleBinding.Namespace, "ClusterRole.Name", existingClusterRoleBinding.Name)
-				return ctrl.Result{}, err
+	return nil, narrowedRoleRefs
+}
+
+// resolveNamespaces returns all existing namespaces, which are selected by the
+// namespaces list and the namespace selector of the NamespaceRoleBinding.
+// Namespaces which do not exist are skipped, the bindings are created as soon
+// as the namespace is created.
+func (r *NamespaceRoleBindingReconciler) resolveNamespaces(ctx context.Context, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) ([]string, error) {
+	namespaces, err := resolveNamespaces(ctx, r.Client, namespaceRoleBinding.Spec.Namespaces, namespaceRoleBinding.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingNamespaces []string
+	for _, namespace := range namespaces {
+		if err := r.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
+			if errors.IsNotFound(err) {
+				continue
 			}
+			return nil, err
 		}
+
+		existingNamespaces = append(existingNamespaces, namespace)
 	}
 
-	for _, existingRoleBinding := range existingRoleBindings.Items {
-		if !wasProcessedNRB(existingRoleBinding.Namespace, existingRoleBinding.Name, processedRoleBindings) {
-			if err := r.Delete(ctx, &existingRoleBinding); err != nil {
-				log.Error(err, "Failed to delete RoleBindingBinding", "RoleBinding.Namespace", existingRoleBinding.Namespace, "RoleBinding.Name", existingRoleBinding.Name)
-				return ctrl.Result{}, err
-			}
+	return existingNamespaces, nil
+}
+
+// expandSubjects expands the variables in the provided subjects for the
+// namespace. The namespace is only fetched when a subject contains a variable.
+func (r *NamespaceRoleBindingReconciler) expandSubjects(ctx context.Context, subjects []rbacv1.Subject, namespace string) ([]rbacv1.Subject, error) {
+	if len(withoutTemplatedSubjects(subjects)) == len(subjects) {
+		return subjects, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, err
+	}
+
+	return expandSubjects(subjects, ns)
+}
+
+// getBindingName returns the name of the ClusterRoleBindings / RoleBindings for
+// the provided role reference. If the NamespaceRoleBinding uses the roleRef
+// field, the name of the NamespaceRoleBinding is used. If it uses the roleRefs
+// field, the kind and name of the referenced role are appended, so that the
+// bindings for different roles do not conflict.
+func getBindingName(namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, roleRef kobsiov1alpha2.NamespaceRoleBindingRoleRef) string {
+	if len(namespaceRoleBinding.Spec.RoleRefs) == 0 {
+		return namespaceRoleBinding.Name
+	}
+
+	return fmt.Sprintf("%s-%s-%s", namespaceRoleBinding.Name, strings.ToLower(string(roleRef.Kind)), roleRef.Name)
+}
+
+// setBindingOwner sets the NamespaceRoleBinding as controller of the provided
+// ClusterRoleBinding / RoleBinding, so that the access is revoked when the
+// NamespaceRoleBinding is deleted. Previous versions of the operator set the
+// NamespaceRole as controller. These owner references are removed, so that
+// existing ClusterRoleBindings / RoleBindings are re-parented in the next
+// reconciliation.
+func setBindingOwner(namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding, obj client.Object, scheme *runtime.Scheme) error {
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if isNamespaceRoleOwnerReference(ownerReference) {
+			continue
 		}
+
+		ownerReferences = append(ownerReferences, ownerReference)
 	}
+	obj.SetOwnerReferences(ownerReferences)
 
-	namespaceRoleBinding.Status.Selector = fmt.Sprintf("%s=%s", selectorLabelKeyNRB, namespaceRoleBinding.Name)
-	namespaceRoleBinding.Status.ClusterRoleBindings = processedClusterRoleBindings
-	namespaceRoleBinding.Status.RoleBindings = processedRoleBindings
+	return ctrl.SetControllerReference(namespaceRoleBinding, obj, scheme)
+}
 
-	err = r.Status().Update(ctx, namespaceRoleBinding)
+// checkBindingOwner returns an error when the provided ClusterRoleBinding /
+// RoleBinding already exists, but was not created by the operator (see
+// isGeneratedBinding). Otherwise an existing binding (e.g. "cluster-admin")
+// would be overwritten and garbage collected with the NamespaceRoleBinding.
+func checkBindingOwner(kind string, obj client.Object, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) error {
+	if obj.GetCreationTimestamp().Time.IsZero() || isGeneratedBinding(obj, namespaceRoleBinding) {
+		return nil
+	}
+
+	return fmt.Errorf("%s %q already exists and is not managed by the NamespaceRoleBinding", kind, obj.GetName())
+}
+
+// isGeneratedBinding returns true when the provided ClusterRoleBinding /
+// RoleBinding was created by the operator. This is the case when it is
+// controlled by the provided NamespaceRoleBinding, has the
+// "kobs.io/namespacerolebinding" label or is owned by a NamespaceRole, like the
+// bindings created by previous versions of the operator.
+func isGeneratedBinding(obj client.Object, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) bool {
+	if metav1.IsControlledBy(obj, namespaceRoleBinding) {
+		return true
+	}
+
+	if _, ok := obj.GetLabels()[selectorLabelKeyNRB]; ok {
+		return true
+	}
+
+	for _, ownerReference := range obj.GetOwnerReferences() {
+		if isNamespaceRoleOwnerReference(ownerReference) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNamespaceRoleOwnerReference returns true when the provided owner reference
+// references a NamespaceRole.
+func isNamespaceRoleOwnerReference(ownerReference metav1.OwnerReference) bool {
+	gv, err := schema.ParseGroupVersion(ownerReference.APIVersion)
+	return err == nil && gv.Group == kobsiov1alpha2.GroupVersion.Group && ownerReference.Kind == "NamespaceRole"
+}
+
+// resolveBindingName returns the name, which should be used for a
+// ClusterRoleBinding / RoleBinding with the provided name and roleRef. Since the
+// roleRef of an existing binding can not be changed, an alternate name with a
+// hash of the roleRef is returned, when the binding with the provided name
+// references another role. An existing binding with the alternate name is
+// preferred, so that the bindings do not flip between both names.
+//
+// The getRoleRef function must return the roleRef of the existing binding with
+// the provided name or nil when the binding doesn't exist.
+func resolveBindingName(name string, roleRef rbacv1.RoleRef, getRoleRef func(name string) (*rbacv1.RoleRef, error)) (string, error) {
+	alternateName := getAlternateBindingName(name, roleRef)
+
+	existingRoleRef, err := getRoleRef(alternateName)
 	if err != nil {
-		log.Error(err, "Failed to update status")
-		return ctrl.Result{}, err
+		return "", err
+	}
+	if existingRoleRef != nil && *existingRoleRef == roleRef {
+		return alternateName, nil
+	}
+
+	existingRoleRef, err = getRoleRef(name)
+	if err != nil {
+		return "", err
+	}
+	if existingRoleRef == nil || *existingRoleRef == roleRef {
+		return name, nil
+	}
+
+	return alternateName, nil
+}
+
+// getAlternateBindingName returns the provided name with a hash of the roleRef
+// as suffix.
+func getAlternateBindingName(name string, roleRef rbacv1.RoleRef) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(fmt.Sprintf("%s/%s/%s", roleRef.APIGroup, roleRef.Kind, roleRef.Name)))
+
+	return fmt.Sprintf("%s-%08x", name, hash.Sum32())
+}
+
+// resolveClusterRoleBindingName returns the name for the ClusterRoleBinding
+// with the provided name and roleRef, see resolveBindingName.
+func (r *NamespaceRoleBindingReconciler) resolveClusterRoleBindingName(ctx context.Context, name string, roleRef rbacv1.RoleRef) (string, error) {
+	return resolveBindingName(name, roleRef, func(name string) (*rbacv1.RoleRef, error) {
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name}, clusterRoleBinding); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+
+		return &clusterRoleBinding.RoleRef, nil
+	})
+}
+
+// resolveRoleBindingName returns the name for the RoleBinding with the
+// provided namespace, name and roleRef, see resolveBindingName.
+func (r *NamespaceRoleBindingReconciler) resolveRoleBindingName(ctx context.Context, namespace, name string, roleRef rbacv1.RoleRef) (string, error) {
+	return resolveBindingName(name, roleRef, func(name string) (*rbacv1.RoleRef, error) {
+		roleBinding := &rbacv1.RoleBinding{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, roleBinding); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+
+		return &roleBinding.RoleRef, nil
+	})
+}
+
+// getBindingLabels returns the labels for the ClusterRoleBindings /
+// RoleBindings of the provided NamespaceRoleBinding. Besides the name the UID
+// is used, so that the objects of a NamespaceRoleBinding can be identified
+// even when a NamespaceRoleBinding with the same name is recreated.
+func getBindingLabels(namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) map[string]string {
+	return map[string]string{
+		selectorLabelKeyNRB:    namespaceRoleBinding.Name,
+		selectorLabelKeyNRBUID: string(namespaceRoleBinding.UID),
+	}
+}
+
+// isControlledByOtherNamespaceRoleBinding returns true when the provided object
+// is controlled by another NamespaceRoleBinding than the provided one.
+func isControlledByOtherNamespaceRoleBinding(obj metav1.Object, namespaceRoleBinding *kobsiov1alpha2.NamespaceRoleBinding) bool {
+	controllerRef := metav1.GetControllerOf(obj)
+	return controllerRef != nil && controllerRef.Kind == "NamespaceRoleBinding" && controllerRef.UID != namespaceRoleBinding.UID
+}
+
+func containsClusterRoleBinding(clusterRoleBindings []rbacv1.ClusterRoleBinding, name string) bool {
+	for _, clusterRoleBinding := range clusterRoleBindings {
+		if clusterRoleBinding.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsRoleBinding(roleBindings []rbacv1.RoleBinding, namespace, name string) bool {
+	for _, roleBinding := range roleBindings {
+		if roleBinding.Namespace == namespace && roleBinding.Name == name {
+			return true
+		}
 	}
 
-	return ctrl.Result{}, nil
+	return false
+}
+
+// namespacedRoleRef is the reference to a Role or ClusterRole, which should be
+// bound via a RoleBinding in the namespace.
+type namespacedRoleRef struct {
+	Namespace string
+	RoleRef   rbacv1.RoleRef
 }
 
-func wasProcessedNRB(namespace, name string, processedRoles []kobsiov1alpha1.NamespaceRoleStatusRoleBinding) bool {
+func wasProcessedNRB(namespace, name string, processedRoles []kobsiov1alpha2.NamespaceRoleStatusRoleBinding) bool {
 	for _, role := range processedRoles {
 		if role.Namespace == namespace && role.Name == name {
 			return true
@@ -221,10 +799,89 @@ func wasProcessedNRB(namespace, name string, processedRoles []kobsiov1alpha1.Nam
 	return false
 }
 
-// SetupWithManager sets up the controller with the Manager.
+// findNamespaceRoleBindingsForNamespace returns a reconcile request for all
+// NamespaceRoleBindings which are narrowed down to namespaces via a selector or
+// pattern, which contain the namespace in their namespaces list or which use
+// variables in their subjects, so that the bindings are updated when a
+// namespace is created, relabeled or deleted.
+func (r *NamespaceRoleBindingReconciler) findNamespaceRoleBindingsForNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	namespaceRoleBindings := &kobsiov1alpha2.NamespaceRoleBindingList{}
+	if err := r.List(ctx, namespaceRoleBindings); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NamespaceRoleBindings", "Namespace.Name", namespace.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, namespaceRoleBinding := range namespaceRoleBindings.Items {
+		spec := namespaceRoleBinding.Spec
+		if contains(spec.Namespaces, namespace.GetName()) || spec.NamespaceSelector != nil || hasNamespacePattern(spec.Namespaces) || len(withoutTemplatedSubjects(spec.Subjects)) != len(spec.Subjects) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceRoleBinding.Name}})
+		}
+	}
+
+	return requests
+}
+
+// indexRoleRefs is the indexer function for the roleRef field index. It
+// returns the names of all NamespaceRoles referenced by the
+// NamespaceRoleBinding.
+func indexRoleRefs(obj client.Object) []string {
+	namespaceRoleBinding, ok := obj.(*kobsiov1alpha2.NamespaceRoleBinding)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, roleRef := range namespaceRoleBinding.Spec.GetRoleRefs() {
+		if roleRef.Kind == kobsiov1alpha2.NamespaceRoleBindingRoleRefKindNamespaceRole {
+			values = appendUnique(values, roleRef.Name)
+		}
+	}
+
+	return values
+}
+
+// findNamespaceRoleBindingsForNamespaceRole returns a reconcile request for all
+// NamespaceRoleBindings which reference the NamespaceRole, so that the bindings
+// are updated when the ClusterRoles / Roles of the NamespaceRole are changed.
+func (r *NamespaceRoleBindingReconciler) findNamespaceRoleBindingsForNamespaceRole(ctx context.Context, namespaceRole client.Object) []reconcile.Request {
+	namespaceRoleBindings := &kobsiov1alpha2.NamespaceRoleBindingList{}
+	if err := r.List(ctx, namespaceRoleBindings, client.MatchingFields{roleRefIndexKey: namespaceRole.GetName()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list NamespaceRoleBindings", "NamespaceRole.Name", namespaceRole.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, namespaceRoleBinding := range namespaceRoleBindings.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: namespaceRoleBinding.Name}})
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager. For Namespaces we
+// only care about created and deleted namespaces and about changed labels. For
+// NamespaceRoles we also care about status changes, because the bindings are
+// created based on the status of the NamespaceRole. Changes to the generated
+// ClusterRoleBindings and RoleBindings are mapped to their NamespaceRoleBinding,
+// so that changes made outside of the operator are reverted.
 func (r *NamespaceRoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kobsiov1alpha2.NamespaceRoleBinding{}, roleRefIndexKey, indexRoleRefs); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&kobsiov1alpha1.NamespaceRoleBinding{}).
-		Watches(&kobsiov1alpha1.NamespaceRole{}, &handler.EnqueueRequestForObject{}).
+		For(&kobsiov1alpha2.NamespaceRoleBinding{}).
+		Watches(
+			&kobsiov1alpha2.NamespaceRole{},
+			handler.EnqueueRequestsFromMapFunc(r.findNamespaceRoleBindingsForNamespaceRole),
+		).
+		Owns(&rbacv1.ClusterRoleBinding{}, builder.WithPredicates(hasLabelPredicate(selectorLabelKeyNRB))).
+		Owns(&rbacv1.RoleBinding{}, builder.WithPredicates(hasLabelPredicate(selectorLabelKeyNRB))).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.findNamespaceRoleBindingsForNamespace),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}),
+		).
 		Complete(r)
 }
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// namespaceRegexPrefix is the prefix of an entry in a list of namespaces,
+	// which marks the entry as regular expression.
+	namespaceRegexPrefix = "re:"
+)
+
+// resolveNamespaces returns the names of all namespaces matching the provided
+// list of namespaces and label selector. An entry in the list of namespaces can
+// be the name of a namespace, a glob pattern (e.g. "team-a-*") or a regular
+// expression prefixed with "re:" (e.g. "re:^preview-[0-9]+$"). The names of
+// namespaces are returned in the order of the list, the namespaces matching a
+// pattern or the label selector are added in alphabetical order.
+func resolveNamespaces(ctx context.Context, c client.Client, entries []string, labelSelector *metav1.LabelSelector) ([]string, error) {
+	var names []string
+	var patterns []string
+
+	for _, entry := range entries {
+		if isNamespacePattern(entry) {
+			patterns = append(patterns, entry)
+		} else {
+			names = appendUnique(names, entry)
+		}
+	}
+
+	if len(patterns) == 0 && labelSelector == nil {
+		return names, nil
+	}
+
+	selector := labels.Nothing()
+	if labelSelector != nil {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces); err != nil {
+		return nil, err
+	}
+
+	var matchedNames []string
+	for _, namespace := range namespaces.Items {
+		if selector.Matches(labels.Set(namespace.Labels)) {
+			matchedNames = append(matchedNames, namespace.Name)
+			continue
+		}
+
+		for _, pattern := range patterns {
+			matches, err := matchNamespace(pattern, namespace.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			if matches {
+				matchedNames = append(matchedNames, namespace.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(matchedNames)
+
+	return appendUnique(names, matchedNames...), nil
+}
+
+// excludeNamespaces removes all namespaces from the provided list of names,
+// which are matching an entry of the exclusion list or the exclusion label
+// selector. Like for resolveNamespaces an entry of the exclusion list can be
+// the name of a namespace, a glob pattern or a regular expression.
+func excludeNamespaces(ctx context.Context, c client.Client, names []string, entries []string, labelSelector *metav1.LabelSelector) ([]string, error) {
+	if len(entries) == 0 && labelSelector == nil {
+		return names, nil
+	}
+
+	excludedNames := make(map[string]bool)
+
+	if labelSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaces := &corev1.NamespaceList{}
+		if err := c.List(ctx, namespaces, &client.ListOptions{LabelSelector: selector}); err != nil {
+			return nil, err
+		}
+
+		for _, namespace := range namespaces.Items {
+			excludedNames[namespace.Name] = true
+		}
+	}
+
+	var filteredNames []string
+	for _, name := range names {
+		if excludedNames[name] {
+			continue
+		}
+
+		excluded := false
+		for _, entry := range entries {
+			matches, err := matchNamespace(entry, name)
+			if err != nil {
+				return nil, err
+			}
+
+			if matches {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			filteredNames = append(filteredNames, name)
+		}
+	}
+
+	return filteredNames, nil
+}
+
+// isNamespacePattern returns true when the provided entry of a list of
+// namespaces is a regular expression or a glob pattern instead of the name of a
+// namespace.
+func isNamespacePattern(entry string) bool {
+	return strings.HasPrefix(entry, namespaceRegexPrefix) || strings.ContainsAny(entry, "*?[")
+}
+
+// hasNamespacePattern returns true when at least one entry of the provided
+// list of namespaces is a regular expression or a glob pattern.
+func hasNamespacePattern(entries []string) bool {
+	for _, entry := range entries {
+		if isNamespacePattern(entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchNamespace checks if the name of a namespace matches the provided entry
+// of a list of namespaces, which can be a name, a glob pattern or a regular
+// expression.
+func matchNamespace(entry, namespace string) (bool, error) {
+	if expr, ok := strings.CutPrefix(entry, namespaceRegexPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression %q: %w", entry, err)
+		}
+
+		return re.MatchString(namespace), nil
+	}
+
+	matches, err := path.Match(entry, namespace)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern %q: %w", entry, err)
+	}
+
+	return matches, nil
+}
+
+// appendUnique appends all values to the provided list, which are not already
+// part of it.
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		if !contains(list, value) {
+			list = append(list, value)
+		}
+	}
+
+	return list
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
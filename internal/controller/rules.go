@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"strings"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// splitRules splits the provided rules into rules for cluster-scoped resources
+// and non-resource URLs and into rules for namespaced resources. The scope of a
+// resource is looked up via the provided RESTMapper, which uses the discovery
+// API of the cluster. Resources which can not be found (e.g. because they
+// contain a wildcard or the CRD is not installed yet) are handled as namespaced
+// resources, so that they never grant cluster-wide access.
+func splitRules(mapper meta.RESTMapper, rules []rbacv1.PolicyRule) ([]rbacv1.PolicyRule, []rbacv1.PolicyRule) {
+	var clusterRules []rbacv1.PolicyRule
+	var namespacedRules []rbacv1.PolicyRule
+
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			clusterRules = append(clusterRules, rule)
+			continue
+		}
+
+		var clusterResources []string
+		var namespacedResources []string
+
+		for _, resource := range rule.Resources {
+			if isClusterScopedResource(mapper, rule.APIGroups, resource) {
+				clusterResources = append(clusterResources, resource)
+			} else {
+				namespacedResources = append(namespacedResources, resource)
+			}
+		}
+
+		if len(clusterResources) > 0 {
+			clusterRule := *rule.DeepCopy()
+			clusterRule.Resources = clusterResources
+			clusterRules = append(clusterRules, clusterRule)
+		}
+
+		if len(namespacedResources) > 0 || len(rule.Resources) == 0 {
+			namespacedRule := *rule.DeepCopy()
+			namespacedRule.Resources = namespacedResources
+			namespacedRules = append(namespacedRules, namespacedRule)
+		}
+	}
+
+	return clusterRules, namespacedRules
+}
+
+// isClusterScopedResource returns true when the provided resource is a
+// cluster-scoped resource in at least one of the provided API groups.
+// Subresources (e.g. "nodes/proxy") are looked up via their parent resource.
+func isClusterScopedResource(mapper meta.RESTMapper, apiGroups []string, resource string) bool {
+	resource, _, _ = strings.Cut(resource, "/")
+	if resource == rbacv1.ResourceAll {
+		return false
+	}
+
+	for _, apiGroup := range apiGroups {
+		if apiGroup == rbacv1.APIGroupAll {
+			continue
+		}
+
+		gvk, err := mapper.KindFor(schema.GroupVersionResource{Group: apiGroup, Resource: resource})
+		if err != nil {
+			continue
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			continue
+		}
+
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			return true
+		}
+	}
+
+	return false
+}
+
+// narrowNamespaceRules restricts all rules for the namespaces resource of the
+// core API group to the provided namespaces via the resourceNames field, so
+// that users can get the namespaces they have access to, without getting access
+// to all namespaces. Rules which already define resourceNames are not changed.
+// If the list of namespaces is empty, the rules for the namespaces resource are
+// removed, because a rule without resourceNames grants access to all
+// namespaces.
+func narrowNamespaceRules(rules []rbacv1.PolicyRule, namespaces []string) []rbacv1.PolicyRule {
+	var narrowedRules []rbacv1.PolicyRule
+
+	for _, rule := range rules {
+		if len(rule.ResourceNames) > 0 || !contains(rule.APIGroups, "") {
+			narrowedRules = append(narrowedRules, rule)
+			continue
+		}
+
+		var namespaceResources []string
+		var otherResources []string
+
+		for _, resource := range rule.Resources {
+			if parent, _, _ := strings.Cut(resource, "/"); parent == "namespaces" {
+				namespaceResources = append(namespaceResources, resource)
+			} else {
+				otherResources = append(otherResources, resource)
+			}
+		}
+
+		if len(namespaceResources) == 0 {
+			narrowedRules = append(narrowedRules, rule)
+			continue
+		}
+
+		if len(otherResources) > 0 {
+			otherRule := *rule.DeepCopy()
+			otherRule.Resources = otherResources
+			narrowedRules = append(narrowedRules, otherRule)
+		}
+
+		if len(namespaces) > 0 {
+			namespaceRule := *rule.DeepCopy()
+			namespaceRule.APIGroups = []string{""}
+			namespaceRule.Resources = namespaceResources
+			namespaceRule.ResourceNames = namespaces
+			narrowedRules = append(narrowedRules, namespaceRule)
+		}
+	}
+
+	return narrowedRules
+}
+
+// applyOverrides applies all overrides matching the provided namespace to the
+// rules of a Role. The overrides are applied in the order of the list, so that
+// a later override with the mode Replace discards the rules of all previous
+// overrides.
+func applyOverrides(rules []rbacv1.PolicyRule, overrides []kobsiov1alpha2.NamespaceRoleOverride, namespace *corev1.Namespace) ([]rbacv1.PolicyRule, error) {
+	for _, override := range overrides {
+		matches, err := matchOverride(override, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if override.Mode == kobsiov1alpha2.NamespaceRoleOverrideModeReplace {
+			rules = override.Rules
+		} else {
+			rules = appendUniqueRules(append([]rbacv1.PolicyRule{}, rules...), override.Rules...)
+		}
+	}
+
+	return rules, nil
+}
+
+// matchOverride returns true when the provided namespace is part of the
+// namespaces list or matches the namespace selector of the override.
+func matchOverride(override kobsiov1alpha2.NamespaceRoleOverride, namespace *corev1.Namespace) (bool, error) {
+	for _, entry := range override.Namespaces {
+		matches, err := matchNamespace(entry, namespace.Name)
+		if err != nil {
+			return false, err
+		}
+
+		if matches {
+			return true, nil
+		}
+	}
+
+	if override.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(override.NamespaceSelector)
+		if err != nil {
+			return false, err
+		}
+
+		return selector.Matches(labels.Set(namespace.Labels)), nil
+	}
+
+	return false, nil
+}
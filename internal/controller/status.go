@@ -0,0 +1,89 @@
+package controller
+
+import (
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// setConditions sets the Ready, Degraded and Progressing conditions based on
+// the result of a reconciliation. If the reconciliation failed the provided
+// error is used as message for the Degraded condition. If the operator is
+// waiting for other resources, the waitingFor message should be set, which is
+// then used as message for the Progressing condition.
+func setConditions(conditions *[]metav1.Condition, generation int64, err error, waitingFor string) {
+	if err != nil {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "ReconcileFailed",
+			Message:            "The last reconciliation failed",
+		})
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeDegraded,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             "ReconcileFailed",
+			Message:            err.Error(),
+		})
+	} else {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             "Reconciled",
+			Message:            "The last reconciliation succeeded",
+		})
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeDegraded,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "Reconciled",
+			Message:            "The last reconciliation succeeded",
+		})
+	}
+
+	if waitingFor != "" {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeProgressing,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             "Waiting",
+			Message:            waitingFor,
+		})
+	} else {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               kobsiov1alpha2.ConditionTypeProgressing,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "Reconciled",
+			Message:            "The operator is not waiting for any resources",
+		})
+	}
+}
+
+// newFailure returns a new failure for the status of a NamespaceRole or
+// NamespaceRoleBinding for the object with the provided kind, namespace and
+// name.
+func newFailure(kind, namespace, name string, err error) kobsiov1alpha2.NamespaceRoleStatusFailure {
+	return kobsiov1alpha2.NamespaceRoleStatusFailure{
+		Kind:      kind,
+		Name:      name,
+		Namespace: namespace,
+		Message:   err.Error(),
+	}
+}
+
+// hasFailed returns true when the list of failures contains the object with the
+// provided kind, namespace and name.
+func hasFailed(kind, namespace, name string, failures []kobsiov1alpha2.NamespaceRoleStatusFailure) bool {
+	for _, failure := range failures {
+		if failure.Kind == kind && failure.Namespace == namespace && failure.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
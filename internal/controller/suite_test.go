@@ -2,22 +2,32 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
-	kobsiov1alpha1 "github.com/kobsio/namespacerole-operator/api/v1alpha1"
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
 	// +kubebuilder:scaffold:imports
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -51,7 +61,7 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	err = kobsiov1alpha1.AddToScheme(scheme.Scheme)
+	err = kobsiov1alpha2.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
@@ -72,18 +82,19 @@ var _ = Describe("ClusterRole and ClusterRoleBinding", func() {
 	Context("When reconciling a resource", func() {
 		ctx := context.Background()
 
-		namespaceRole := &kobsiov1alpha1.NamespaceRole{}
-		namespaceRoleBinding := &kobsiov1alpha1.NamespaceRoleBinding{}
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
 
 		BeforeEach(func() {
 			By("Create NamespaceRole")
 			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, namespaceRole)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &kobsiov1alpha1.NamespaceRole{
+				resource := &kobsiov1alpha2.NamespaceRole{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "kobs-mygroup1",
 					},
-					Spec: kobsiov1alpha1.NamespaceRoleSpec{
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeCluster,
 						Namespaces: []string{"*"},
 						Rules: []rbacv1.PolicyRule{{
 							APIGroups: []string{""},
@@ -98,12 +109,12 @@ var _ = Describe("ClusterRole and ClusterRoleBinding", func() {
 			By("Create NamespaceRoleBinding")
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, namespaceRoleBinding)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &kobsiov1alpha1.NamespaceRoleBinding{
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "kobs-mygroup1",
 					},
-					Spec: kobsiov1alpha1.NamespaceRoleBindingSpec{
-						RoleRef: kobsiov1alpha1.NamespaceRoleBindingSpecRoleRef{
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
 							Name: "kobs-mygroup1",
 						},
 						Subjects: []rbacv1.Subject{{
@@ -119,13 +130,13 @@ var _ = Describe("ClusterRole and ClusterRoleBinding", func() {
 
 		AfterEach(func() {
 			By("Cleanup NamespaceRoleBinding")
-			namespaceRoleBinding := &kobsiov1alpha1.NamespaceRoleBinding{}
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
 			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, namespaceRoleBinding)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
 
 			By("Cleanup NamespaceRole")
-			namespaceRole := &kobsiov1alpha1.NamespaceRole{}
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, namespaceRole)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
@@ -165,7 +176,9 @@ var _ = Describe("ClusterRole and ClusterRoleBinding", func() {
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, roleBinding)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(roleBinding.Name).To(Equal("kobs-mygroup1"))
-			Expect(roleBinding.Labels).To(Equal(map[string]string{"kobs.io/namespacerolebinding": "kobs-mygroup1"}))
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup1"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.Labels).To(Equal(map[string]string{"kobs.io/namespacerolebinding": "kobs-mygroup1", "kobs.io/namespacerolebinding-uid": string(namespaceRoleBinding.UID)}))
 			Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
 				APIGroup: "rbac.authorization.k8s.io",
 				Kind:     "ClusterRole",
@@ -184,18 +197,19 @@ var _ = Describe("Role and RoleBinding", func() {
 	Context("When reconciling a resource", func() {
 		ctx := context.Background()
 
-		namespaceRole := &kobsiov1alpha1.NamespaceRole{}
-		namespaceRoleBinding := &kobsiov1alpha1.NamespaceRoleBinding{}
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
 
 		BeforeEach(func() {
 			By("Create NamespaceRole")
 			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup2"}, namespaceRole)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &kobsiov1alpha1.NamespaceRole{
+				resource := &kobsiov1alpha2.NamespaceRole{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "kobs-mygroup2",
 					},
-					Spec: kobsiov1alpha1.NamespaceRoleSpec{
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
 						Namespaces: []string{"default"},
 						Rules: []rbacv1.PolicyRule{{
 							APIGroups: []string{""},
@@ -210,12 +224,12 @@ var _ = Describe("Role and RoleBinding", func() {
 			By("Create NamespaceRoleBinding")
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup2"}, namespaceRoleBinding)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &kobsiov1alpha1.NamespaceRoleBinding{
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "kobs-mygroup2",
 					},
-					Spec: kobsiov1alpha1.NamespaceRoleBindingSpec{
-						RoleRef: kobsiov1alpha1.NamespaceRoleBindingSpecRoleRef{
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
 							Name: "kobs-mygroup2",
 						},
 						Subjects: []rbacv1.Subject{{
@@ -231,13 +245,13 @@ var _ = Describe("Role and RoleBinding", func() {
 
 		AfterEach(func() {
 			By("Cleanup NamespaceRoleBinding")
-			namespaceRoleBinding := &kobsiov1alpha1.NamespaceRoleBinding{}
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
 			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup2"}, namespaceRoleBinding)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
 
 			By("Cleanup NamespaceRole")
-			namespaceRole := &kobsiov1alpha1.NamespaceRole{}
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
 			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup2"}, namespaceRole)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
@@ -279,7 +293,9 @@ var _ = Describe("Role and RoleBinding", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(roleBinding.Name).To(Equal("kobs-mygroup2"))
 			Expect(roleBinding.Namespace).To(Equal("default"))
-			Expect(roleBinding.Labels).To(Equal(map[string]string{"kobs.io/namespacerolebinding": "kobs-mygroup2"}))
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup2"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.Labels).To(Equal(map[string]string{"kobs.io/namespacerolebinding": "kobs-mygroup2", "kobs.io/namespacerolebinding-uid": string(namespaceRoleBinding.UID)}))
 			Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
 				APIGroup: "rbac.authorization.k8s.io",
 				Kind:     "Role",
@@ -293,3 +309,3384 @@ var _ = Describe("Role and RoleBinding", func() {
 		})
 	})
 })
+
+var _ = Describe("Role for namespaces selected by labels", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create Namespace")
+			namespace := &corev1.Namespace{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "payments"}, namespace)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "payments",
+						Labels: map[string]string{"team": "payments"},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRole")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup3"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup3",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						NamespaceSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"team": "payments"},
+						},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup3"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create a Role in the listed and in the selected namespaces", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup3"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Roles")
+			for _, namespace := range []string{"default", "payments"} {
+				role := &rbacv1.Role{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup3", Namespace: namespace}, role)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role.Labels).To(Equal(map[string]string{"kobs.io/namespacerole": "kobs-mygroup3"}))
+			}
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup3"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.Roles).To(Equal([]kobsiov1alpha2.NamespaceRoleStatusRole{
+				{Name: "kobs-mygroup3", Namespace: "default"},
+				{Name: "kobs-mygroup3", Namespace: "payments"},
+			}))
+		})
+	})
+})
+
+var _ = Describe("Role for namespaces matching a pattern", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup4"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup4",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"kube-*", "re:^defaul.$"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup4"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create a Role in all matching namespaces", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup4"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup4"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.ClusterRoles).To(BeEmpty())
+			Expect(namespaceRole.Status.Roles).To(ContainElements(
+				kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup4", Namespace: "default"},
+				kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup4", Namespace: "kube-public"},
+				kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup4", Namespace: "kube-system"},
+			))
+		})
+	})
+})
+
+var _ = Describe("Role for all namespaces except excluded namespaces", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup5"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup5",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:             kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces:        []string{"*"},
+						ExcludeNamespaces: []string{"kube-*"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup5"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create a Role in all namespaces which are not excluded", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup5"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup5"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.ClusterRoles).To(BeEmpty())
+			Expect(namespaceRole.Status.Roles).To(ContainElement(kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup5", Namespace: "default"}))
+			Expect(namespaceRole.Status.Roles).NotTo(ContainElement(kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup5", Namespace: "kube-system"}))
+		})
+	})
+})
+
+var _ = Describe("Role for namespaces which do not exist", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup6"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup6",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"does-not-exist", "default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup6"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should skip the missing namespaces and report them in the status", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup6"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup6", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup6"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.Roles).To(Equal([]kobsiov1alpha2.NamespaceRoleStatusRole{{Name: "kobs-mygroup6", Namespace: "default"}}))
+			Expect(namespaceRole.Status.MissingNamespaces).To(Equal([]string{"does-not-exist"}))
+			Expect(meta.IsStatusConditionTrue(namespaceRole.Status.Conditions, kobsiov1alpha2.ConditionTypeNamespacesMissing)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(namespaceRole.Status.Conditions, kobsiov1alpha2.ConditionTypeReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(namespaceRole.Status.Conditions, kobsiov1alpha2.ConditionTypeProgressing)).To(BeTrue())
+			Expect(namespaceRole.Status.ObservedGeneration).To(Equal(namespaceRole.Generation))
+		})
+	})
+})
+
+var _ = Describe("ClusterRole and Role for the scope Both", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup7"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup7",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeBoth,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods", "nodes"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup7"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create a ClusterRole for cluster-scoped resources and a Role for namespaced resources", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup7"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check ClusterRole")
+			clusterRole := &rbacv1.ClusterRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup7"}, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRole.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"nodes"},
+				Verbs:     []string{"get", "list"},
+			}}))
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup7", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list"},
+			}}))
+		})
+	})
+})
+
+var _ = Describe("Companion ClusterRole for cluster-scoped rules", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup8"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup8",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default", "kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"namespaces", "pods"},
+							Verbs:     []string{"get"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup8"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create a ClusterRole narrowed to the namespaces of the Roles", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup8"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check ClusterRole")
+			clusterRole := &rbacv1.ClusterRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup8"}, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRole.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups:     []string{""},
+				Resources:     []string{"namespaces"},
+				ResourceNames: []string{"default", "kube-public"},
+				Verbs:         []string{"get"},
+			}}))
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup8", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get"},
+			}}))
+		})
+	})
+})
+
+var _ = Describe("RoleBinding for a referenced ClusterRole", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		clusterRole := &rbacv1.ClusterRole{}
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create ClusterRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-view"}, clusterRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-view",
+					},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"pods"},
+						Verbs:     []string{"get", "list"},
+					}},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRole")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup9",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:          kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces:     []string{"default", "kube-public"},
+						ClusterRoleRef: "kobs-view",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup9",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup9",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup9",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+
+			By("Cleanup ClusterRole")
+			clusterRole := &rbacv1.ClusterRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-view"}, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, clusterRole)).To(Succeed())
+		})
+
+		It("Should create RoleBindings for the referenced ClusterRole without creating Roles", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup9"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup9"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Roles")
+			for _, namespace := range []string{"default", "kube-public"} {
+				role := &rbacv1.Role{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9", Namespace: namespace}, role)
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+			}
+
+			By("Check RoleBindings")
+			for _, namespace := range []string{"default", "kube-public"} {
+				roleBinding := &rbacv1.RoleBinding{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup9", Namespace: namespace}, roleBinding)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "ClusterRole",
+					Name:     "kobs-view",
+				}))
+			}
+		})
+	})
+})
+
+var _ = Describe("Role with included rules", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			By("Create ClusterRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-base-reader"}, &rbacv1.ClusterRole{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &rbacv1.ClusterRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-base-reader",
+					},
+					Rules: []rbacv1.PolicyRule{{
+						APIGroups: []string{""},
+						Resources: []string{"configmaps"},
+						Verbs:     []string{"get", "list"},
+					}},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create included NamespaceRole")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup10-base"}, &kobsiov1alpha2.NamespaceRole{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup10-base",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"services"},
+							Verbs:     []string{"get", "list"},
+						}},
+						Includes: []kobsiov1alpha2.NamespaceRoleInclude{{
+							Kind: kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole,
+							Name: "kobs-base-reader",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRole")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup10"}, &kobsiov1alpha2.NamespaceRole{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup10",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+						Includes: []kobsiov1alpha2.NamespaceRoleInclude{{
+							Kind: kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole,
+							Name: "kobs-mygroup10-base",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoles")
+			for _, name := range []string{"kobs-mygroup10", "kobs-mygroup10-base"} {
+				namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, namespaceRole)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+			}
+
+			By("Cleanup ClusterRole")
+			clusterRole := &rbacv1.ClusterRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-base-reader"}, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, clusterRole)).To(Succeed())
+		})
+
+		It("Should merge the rules of the included NamespaceRoles and ClusterRoles into the Role", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup10"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup10", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list"},
+			}, {
+				APIGroups: []string{""},
+				Resources: []string{"services"},
+				Verbs:     []string{"get", "list"},
+			}, {
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get", "list"},
+			}}))
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoles including each other", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		BeforeEach(func() {
+			By("Create NamespaceRoles")
+			for _, names := range [][]string{{"kobs-mygroup11-a", "kobs-mygroup11-b"}, {"kobs-mygroup11-b", "kobs-mygroup11-a"}} {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: names[0]}, &kobsiov1alpha2.NamespaceRole{})
+				if err != nil && errors.IsNotFound(err) {
+					resource := &kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: names[0],
+						},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"default"},
+							Includes: []kobsiov1alpha2.NamespaceRoleInclude{{
+								Kind: kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole,
+								Name: names[1],
+							}},
+						},
+					}
+					Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+				}
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoles")
+			for _, name := range []string{"kobs-mygroup11-a", "kobs-mygroup11-b"} {
+				namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, namespaceRole)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+			}
+		})
+
+		It("Should detect the cycle and report it in the status", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup11-a"}})
+			Expect(err).To(MatchError(ContainSubstring("include cycle detected: kobs-mygroup11-a -> kobs-mygroup11-b -> kobs-mygroup11-a")))
+
+			By("Check Status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup11-a"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.Failures).To(HaveLen(1))
+			Expect(meta.IsStatusConditionTrue(namespaceRole.Status.Conditions, kobsiov1alpha2.ConditionTypeDegraded)).To(BeTrue())
+
+			By("Check Role")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup11-a", Namespace: "default"}, &rbacv1.Role{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Role with rules from ClusterRoles selected by labels", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		clusterRoles := []*rbacv1.ClusterRole{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "kobs-aggregate-a",
+				Labels: map[string]string{"example.com/aggregate-to-mygroup12": "true"},
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"example.com"},
+				Resources: []string{"widgets"},
+				Verbs:     []string{"get"},
+			}},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "kobs-aggregate-b",
+				Labels: map[string]string{"example.com/aggregate-to-mygroup12": "true"},
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"example.com"},
+				Resources: []string{"gadgets"},
+				Verbs:     []string{"get"},
+			}},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "kobs-aggregate-c",
+			},
+			Rules: []rbacv1.PolicyRule{{
+				APIGroups: []string{"example.com"},
+				Resources: []string{"gizmos"},
+				Verbs:     []string{"get"},
+			}},
+		}}
+
+		BeforeEach(func() {
+			By("Create ClusterRoles")
+			for _, clusterRole := range clusterRoles {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: clusterRole.Name}, &rbacv1.ClusterRole{})
+				if err != nil && errors.IsNotFound(err) {
+					Expect(k8sClient.Create(ctx, clusterRole.DeepCopy())).To(Succeed())
+				}
+			}
+
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup12"}, &kobsiov1alpha2.NamespaceRole{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup12",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						RuleSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{"example.com/aggregate-to-mygroup12": "true"},
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup12"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+
+			By("Cleanup ClusterRoles")
+			for _, clusterRole := range clusterRoles {
+				Expect(k8sClient.Delete(ctx, clusterRole.DeepCopy())).To(Succeed())
+			}
+		})
+
+		It("Should add the rules of all matching ClusterRoles to the Role", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup12"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup12", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{"example.com"},
+				Resources: []string{"widgets"},
+				Verbs:     []string{"get"},
+			}, {
+				APIGroups: []string{"example.com"},
+				Resources: []string{"gadgets"},
+				Verbs:     []string{"get"},
+			}}))
+		})
+	})
+})
+
+var _ = Describe("Roles with per-namespace overrides", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup13"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup13",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default", "kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+						Overrides: []kobsiov1alpha2.NamespaceRoleOverride{{
+							Namespaces: []string{"kube-*"},
+							Mode:       kobsiov1alpha2.NamespaceRoleOverrideModeAppend,
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{""},
+								Resources: []string{"pods"},
+								Verbs:     []string{"delete"},
+							}},
+						}, {
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"kubernetes.io/metadata.name": "default"},
+							},
+							Mode: kobsiov1alpha2.NamespaceRoleOverrideModeReplace,
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{""},
+								Resources: []string{"pods"},
+								Verbs:     []string{"get"},
+							}},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup13"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should apply the overrides to the Roles in the matching namespaces", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup13"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Role with appended rules")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup13", Namespace: "kube-public"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get", "list"},
+			}, {
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"delete"},
+			}}))
+
+			By("Check Role with replaced rules")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup13", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"pods"},
+				Verbs:     []string{"get"},
+			}}))
+		})
+	})
+})
+
+var _ = Describe("Roles with templated rules", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup14"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup14",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default", "kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups:     []string{""},
+							Resources:     []string{"secrets"},
+							ResourceNames: []string{"${namespace}-db-credentials", "${label:kubernetes.io/metadata.name}-api-key"},
+							Verbs:         []string{"get"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup14"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should expand the variables for each namespace", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup14"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Roles")
+			for _, namespace := range []string{"default", "kube-public"} {
+				role := &rbacv1.Role{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup14", Namespace: namespace}, role)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+					APIGroups:     []string{""},
+					Resources:     []string{"secrets"},
+					ResourceNames: []string{namespace + "-db-credentials", namespace + "-api-key"},
+					Verbs:         []string{"get"},
+				}}))
+			}
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding for multiple roles", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup15",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup15",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+							Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindNamespaceRole,
+							Name: "kobs-mygroup15",
+						}, {
+							Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+							Name: "view",
+						}},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup15",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create bindings for all referenced roles", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup15"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup15"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBinding")
+			roleBinding := &rbacv1.RoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15-namespacerole-kobs-mygroup15", Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "Role",
+				Name:     "kobs-mygroup15",
+			}))
+
+			By("Check ClusterRoleBinding")
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15-clusterrole-view"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     "view",
+			}))
+
+			By("Check Status")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup15"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.RoleRefs).To(Equal([]kobsiov1alpha2.NamespaceRoleBindingStatusRoleRef{{
+				Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindNamespaceRole,
+				Name: "kobs-mygroup15",
+				RoleBindings: []kobsiov1alpha2.NamespaceRoleStatusRoleBinding{{
+					Name:      "kobs-mygroup15-namespacerole-kobs-mygroup15",
+					Namespace: "default",
+				}},
+			}, {
+				Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+				Name: "view",
+				ClusterRoleBindings: []kobsiov1alpha2.NamespaceRoleStatusRoleBinding{{
+					Name: "kobs-mygroup15-clusterrole-view",
+				}},
+			}}))
+
+			By("Cleanup ClusterRoleBinding")
+			Expect(k8sClient.Delete(ctx, clusterRoleBinding)).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding narrowed to namespaces", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup16",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default", "kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"namespaces", "pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup16",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+							Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindNamespaceRole,
+							Name: "kobs-mygroup16",
+						}, {
+							Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+							Name: "view",
+						}},
+						Namespaces: []string{"default"},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup16",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should only create RoleBindings in the selected namespaces", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup16"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup16"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBindings for the NamespaceRole")
+			roleBinding := &rbacv1.RoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16-namespacerole-kobs-mygroup16", Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16-namespacerole-kobs-mygroup16", Namespace: "kube-public"}, roleBinding)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Check RoleBinding for the ClusterRole")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16-clusterrole-view", Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.RoleRef).To(Equal(rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     "view",
+			}))
+
+			By("Check ClusterRoleBindings")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16-clusterrole-view"}, &rbacv1.ClusterRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16"}, &rbacv1.ClusterRole{})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup16-namespacerole-kobs-mygroup16"}, &rbacv1.ClusterRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding with templated subjects", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup17"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup17",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default", "kube-public"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup17"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup17",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup17",
+						},
+						Subjects: []rbacv1.Subject{{
+							Kind:      "ServiceAccount",
+							Name:      "ci",
+							Namespace: "${namespace}",
+						}, {
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/${label:kubernetes.io/metadata.name}",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup17"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup17"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should expand the subjects for each namespace", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup17"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup17"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBindings")
+			for _, namespace := range []string{"default", "kube-public"} {
+				roleBinding := &rbacv1.RoleBinding{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup17", Namespace: namespace}, roleBinding)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(roleBinding.Subjects).To(Equal([]rbacv1.Subject{{
+					Kind:      "ServiceAccount",
+					Name:      "ci",
+					Namespace: namespace,
+				}, {
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "Group",
+					Name:     "group:default/" + namespace,
+				}}))
+			}
+		})
+	})
+})
+
+var _ = Describe("Time-bounded NamespaceRoleBinding", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup18",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"*"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup18",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup18",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "User",
+							Name:     "on-call",
+						}},
+						ValidUntil: &metav1.Time{Time: time.Now().Add(time.Hour)},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should create the RoleBinding until it expires", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup18"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			result, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup18"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", time.Hour, time.Minute))
+
+			By("Check RoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Expire NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.NextTransitionTime).NotTo(BeNil())
+			namespaceRoleBinding.Spec.ValidUntil = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+			Expect(k8sClient.Update(ctx, namespaceRoleBinding)).To(Succeed())
+
+			result, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup18"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			By("Check RoleBinding was revoked")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup18"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionFalse(namespaceRoleBinding.Status.Conditions, kobsiov1alpha2.ConditionTypeActive)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Scheduled NamespaceRoleBinding", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup19",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup19",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup19",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/contractors",
+						}},
+						Schedule: &kobsiov1alpha2.NamespaceRoleBindingSchedule{
+							Cron:     fmt.Sprintf("0 0 1 %d *", time.Now().AddDate(0, 6, 0).Month()),
+							Duration: metav1.Duration{Duration: time.Hour},
+							TimeZone: "Europe/Berlin",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should not create the RoleBinding outside of the schedule", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup19"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			result, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup19"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			By("Check RoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Check Status")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup19"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.NextTransitionTime).NotTo(BeNil())
+			Expect(meta.IsStatusConditionFalse(namespaceRoleBinding.Status.Conditions, kobsiov1alpha2.ConditionTypeActive)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("AccessRequest", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		accessRequest := &kobsiov1alpha2.AccessRequest{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup20",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"*"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create AccessRequest")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.AccessRequest{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup20",
+					},
+					Spec: kobsiov1alpha2.AccessRequestSpec{
+						RoleRef: kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup20",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "User",
+							Name:     "bob",
+						}},
+						Duration:    metav1.Duration{Duration: time.Hour},
+						Reason:      "Debug failing pods",
+						RequestedBy: "carol",
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup20"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup AccessRequest")
+			accessRequest := &kobsiov1alpha2.AccessRequest{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, accessRequest)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should only create the NamespaceRoleBinding after a valid approval", func() {
+			controllerAccessRequestReconciler := &AccessRequestReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			// The user and time of an approval are set by the admission
+			// webhook, which is not running in the test environment.
+			By("Approve AccessRequest by subject and by creator")
+			accessRequest := &kobsiov1alpha2.AccessRequest{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)
+			Expect(err).NotTo(HaveOccurred())
+			accessRequest.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{
+				{User: "bob", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+				{User: "carol", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+			}
+			Expect(k8sClient.Update(ctx, accessRequest)).To(Succeed())
+
+			_, err = controllerAccessRequestReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup20"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhasePending))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup20"}, &kobsiov1alpha2.NamespaceRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Approve AccessRequest by approver")
+			approvedAt := metav1.Now()
+			accessRequest.Spec.Approvals = append(accessRequest.Spec.Approvals, kobsiov1alpha2.AccessRequestApproval{
+				User:     "alice",
+				Decision: kobsiov1alpha2.AccessRequestDecisionApproved,
+				Comment:  "Approved for the incident",
+				Time:     approvedAt,
+			})
+			Expect(k8sClient.Update(ctx, accessRequest)).To(Succeed())
+
+			Eventually(func() kobsiov1alpha2.AccessRequestPhase {
+				_, err := controllerAccessRequestReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup20"}})
+				Expect(err).NotTo(HaveOccurred())
+
+				accessRequest := &kobsiov1alpha2.AccessRequest{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)).To(Succeed())
+				return accessRequest.Status.Phase
+			}, 10*time.Second, time.Second).Should(Equal(kobsiov1alpha2.AccessRequestPhaseApproved))
+
+			By("Check NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup20"}, accessRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.ApprovedBy).To(Equal([]string{"alice"}))
+			Expect(accessRequest.Status.NamespaceRoleBinding).To(Equal("accessrequest-kobs-mygroup20"))
+			Expect(accessRequest.Status.ExpiresAt.Time).To(BeTemporally("~", approvedAt.Add(time.Hour), time.Second))
+
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup20"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metav1.IsControlledBy(namespaceRoleBinding, accessRequest)).To(BeTrue())
+			Expect(namespaceRoleBinding.Spec.RoleRef.Name).To(Equal("kobs-mygroup20"))
+			Expect(namespaceRoleBinding.Spec.ValidUntil.Time).To(BeTemporally("~", accessRequest.Status.ExpiresAt.Time, time.Second))
+		})
+	})
+})
+
+var _ = Describe("Break-glass NamespaceRoleBinding", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup21",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{"*"},
+							Resources: []string{"*"},
+							Verbs:     []string{"*"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup21",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup21",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "User",
+							Name:     "on-call",
+						}},
+						ValidUntil: &metav1.Time{Time: time.Now().Add(24 * time.Hour)},
+						BreakGlass: &kobsiov1alpha2.NamespaceRoleBindingBreakGlass{
+							Justification: "INC-1234",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should limit the access and report it until it is acknowledged", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup21"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			recorder := record.NewFakeRecorder(10)
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client:           k8sClient,
+				Scheme:           k8sClient.Scheme(),
+				Recorder:         recorder,
+				BreakGlassMaxTTL: 30 * time.Minute,
+			}
+			result, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup21"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 30*time.Minute, time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("INC-1234")))
+
+			By("Check RoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check Status")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.BreakGlass).NotTo(BeNil())
+			Expect(meta.IsStatusConditionTrue(namespaceRoleBinding.Status.Conditions, kobsiov1alpha2.ConditionTypeBreakGlass)).To(BeTrue())
+
+			By("Acknowledge break-glass access")
+			Expect(namespaceRoleBinding.Annotations).To(HaveKey("kobs.io/break-glass-activated-at"))
+			namespaceRoleBinding.Annotations["kobs.io/break-glass-acknowledged"] = namespaceRoleBinding.Status.BreakGlass.ActivatedAt.UTC().Format(time.RFC3339)
+			Expect(k8sClient.Update(ctx, namespaceRoleBinding)).To(Succeed())
+
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup21"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup21"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.IsStatusConditionFalse(namespaceRoleBinding.Status.Conditions, kobsiov1alpha2.ConditionTypeBreakGlass)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding ownership", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup22",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup22",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup22",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup22",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should re-parent RoleBindings owned by the NamespaceRole", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup22"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Create RoleBinding owned by the NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kobs-mygroup22",
+					Namespace: "default",
+				},
+				RoleRef: rbacv1.RoleRef{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "Role",
+					Name:     "kobs-mygroup22",
+				},
+			}
+			Expect(ctrl.SetControllerReference(namespaceRole, roleBinding, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, roleBinding)).To(Succeed())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup22"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup22", Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metav1.IsControlledBy(roleBinding, namespaceRoleBinding)).To(BeTrue())
+			Expect(metav1.IsControlledBy(roleBinding, namespaceRole)).To(BeFalse())
+			Expect(roleBinding.OwnerReferences).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding with changed roleRef", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRoles")
+			for name, namespace := range map[string]string{"kobs-mygroup23a": "default", "kobs-mygroup23b": "kube-public"} {
+				err := k8sClient.Get(ctx, types.NamespacedName{Name: name}, &kobsiov1alpha2.NamespaceRole{})
+				if err != nil && errors.IsNotFound(err) {
+					resource := &kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: name,
+						},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{namespace},
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{""},
+								Resources: []string{"pods"},
+								Verbs:     []string{"get", "list"},
+							}},
+						},
+					}
+					Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+				}
+			}
+
+			By("Create NamespaceRoleBinding")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup23",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup23a",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup23",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRoles")
+			for _, name := range []string{"kobs-mygroup23a", "kobs-mygroup23b"} {
+				namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: name}, namespaceRole)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+			}
+		})
+
+		It("Should delete the RoleBindings for the old roleRef", func() {
+			By("Reconciling NamespaceRoles")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			for _, name := range []string{"kobs-mygroup23a", "kobs-mygroup23b"} {
+				_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup23"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Change roleRef")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.Selector).To(Equal(fmt.Sprintf("kobs.io/namespacerolebinding=kobs-mygroup23,kobs.io/namespacerolebinding-uid=%s", namespaceRoleBinding.UID)))
+			namespaceRoleBinding.Spec.RoleRef.Name = "kobs-mygroup23b"
+			Expect(k8sClient.Update(ctx, namespaceRoleBinding)).To(Succeed())
+
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup23"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBindings")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23", Namespace: "kube-public"}, &rbacv1.RoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup23", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBindings for NamespaceRole", func() {
+	Context("When a NamespaceRole is changed", func() {
+		ctx := context.Background()
+
+		It("Should return all NamespaceRoleBindings referencing the NamespaceRole", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithIndex(&kobsiov1alpha2.NamespaceRoleBinding{}, roleRefIndexKey, indexRoleRefs).
+				WithObjects(
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup24a"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{Name: "kobs-mygroup24"},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup24b"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{
+								{Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole, Name: "view"},
+								{Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindNamespaceRole, Name: "kobs-mygroup24"},
+							},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup24c"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{
+								{Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole, Name: "kobs-mygroup24"},
+							},
+						},
+					},
+				).
+				Build()
+
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			requests := controllerNamespaceRoleBindingReconciler.findNamespaceRoleBindingsForNamespaceRole(ctx, &kobsiov1alpha2.NamespaceRole{ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup24"}})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup24a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup24b"}},
+			))
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding with changed roles", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+		namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup25",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeCluster,
+						Namespaces: []string{"*"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			By("Create NamespaceRoleBinding")
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRoleBinding)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup25",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+						RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{
+							Name: "kobs-mygroup25",
+						},
+						Namespaces: []string{"default"},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "group:default/mygroup25",
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRoleBinding)).To(Succeed())
+
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should recreate the RoleBinding when the roleRef changes", func() {
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup25"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup25"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			roleBinding := &rbacv1.RoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25", Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.RoleRef.Kind).To(Equal("ClusterRole"))
+
+			By("Change scope of NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			namespaceRole.Spec.Scope = kobsiov1alpha2.NamespaceRoleScopeNamespaced
+			namespaceRole.Spec.Namespaces = []string{"default"}
+			Expect(k8sClient.Update(ctx, namespaceRole)).To(Succeed())
+
+			_, err = controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup25"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup25"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check RoleBindings")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.RoleBindings).To(HaveLen(1))
+			Expect(namespaceRoleBinding.Status.RoleBindings[0].Name).NotTo(Equal("kobs-mygroup25"))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: namespaceRoleBinding.Status.RoleBindings[0].Name, Namespace: "default"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.RoleRef.Kind).To(Equal("Role"))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup25", Namespace: "default"}, &rbacv1.RoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Drift of generated Roles", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+
+		BeforeEach(func() {
+			By("Create NamespaceRole")
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup26"}, namespaceRole)
+			if err != nil && errors.IsNotFound(err) {
+				resource := &kobsiov1alpha2.NamespaceRole{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "kobs-mygroup26",
+					},
+					Spec: kobsiov1alpha2.NamespaceRoleSpec{
+						Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+						Namespaces: []string{"default"},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get", "list"},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			By("Cleanup NamespaceRole")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup26"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(ctx, namespaceRole)).To(Succeed())
+		})
+
+		It("Should restore changed and deleted Roles", func() {
+			By("Reconciling NamespaceRole")
+			recorder := record.NewFakeRecorder(10)
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup26"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("Change Role")
+			role := &rbacv1.Role{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup26", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			role.Rules[0].Verbs = []string{"*"}
+			Expect(k8sClient.Update(ctx, role)).To(Succeed())
+
+			_, err = controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup26"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("DriftDetected")))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup26", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules[0].Verbs).To(Equal([]string{"get", "list"}))
+
+			By("Delete Role")
+			Expect(k8sClient.Delete(ctx, role)).To(Succeed())
+
+			_, err = controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup26"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("DriftDetected")))
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup26", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoles for Namespace", func() {
+	Context("When a namespace is created, relabeled or deleted", func() {
+		ctx := context.Background()
+
+		It("Should return all NamespaceRoles depending on the namespace", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithObjects(
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27a"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"team-a"},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27b"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope: kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"team": "a"},
+							},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27c"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"team-*"},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27d"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:             kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces:        []string{"team-b"},
+							ExcludeNamespaces: []string{"team-?-dev"},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27e"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"team-b"},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup27f"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope: kobsiov1alpha2.NamespaceRoleScopeCluster,
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"team": "a"},
+							},
+						},
+					},
+				).
+				Build()
+
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			requests := controllerNamespaceRoleReconciler.findNamespaceRolesForNamespace(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27b"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27c"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27d"}},
+			))
+
+			requests = controllerNamespaceRoleReconciler.findNamespaceRolesForNamespace(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27b"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27c"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup27d"}},
+			))
+		})
+	})
+})
+
+var _ = Describe("NamespaceRole with a failing namespace", func() {
+	Context("When the Role for one namespace can not be created", func() {
+		ctx := context.Background()
+
+		It("Should reconcile the other namespaces and report the failure", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRole{}).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-d"}},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup28"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"team-a", "team-b", "team-c"},
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{""},
+								Resources: []string{"pods"},
+								Verbs:     []string{"get", "list"},
+							}},
+						},
+					},
+					&rbacv1.Role{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "kobs-mygroup28",
+							Namespace: "team-d",
+							Labels:    map[string]string{selectorLabelKeyNR: "kobs-mygroup28"},
+						},
+					},
+				).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if _, ok := obj.(*rbacv1.Role); ok && obj.GetNamespace() == "team-b" {
+							return fmt.Errorf("create failed")
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup28"}})
+			Expect(err).To(HaveOccurred())
+
+			By("Check Roles")
+			for _, namespace := range []string{"team-a", "team-c"} {
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup28", Namespace: namespace}, &rbacv1.Role{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup28", Namespace: "team-b"}, &rbacv1.Role{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup28", Namespace: "team-d"}, &rbacv1.Role{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup28"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.Roles).To(ConsistOf(
+				kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup28", Namespace: "team-a"},
+				kobsiov1alpha2.NamespaceRoleStatusRole{Name: "kobs-mygroup28", Namespace: "team-c"},
+			))
+			Expect(namespaceRole.Status.Failures).To(HaveLen(1))
+			Expect(namespaceRole.Status.Failures[0].Kind).To(Equal("Role"))
+			Expect(namespaceRole.Status.Failures[0].Namespace).To(Equal("team-b"))
+			Expect(namespaceRole.Status.Failures[0].Message).To(ContainSubstring("create failed"))
+			Expect(meta.IsStatusConditionTrue(namespaceRole.Status.Conditions, kobsiov1alpha2.ConditionTypeDegraded)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Companion ClusterRole with an existing name", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		newRESTMapper := func() meta.RESTMapper {
+			restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+			restMapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+			restMapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), meta.RESTScopeNamespace)
+			return restMapper
+		}
+
+		rules := []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"namespaces", "pods"},
+			Verbs:     []string{"get"},
+		}}
+
+		It("Should not adopt a ClusterRole, which was not created by the operator", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithRESTMapper(newRESTMapper()).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRole{}).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+					&rbacv1.ClusterRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "view", CreationTimestamp: metav1.Now()},
+						Rules: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"configmaps"},
+							Verbs:     []string{"get"},
+						}},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "view"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:      kobsiov1alpha2.NamespaceRoleScopeNamespaced,
+							Namespaces: []string{"default"},
+							Rules:      rules,
+						},
+					},
+				).
+				Build()
+
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "view"}})
+			Expect(err).To(MatchError(ContainSubstring("not managed by the NamespaceRole")))
+
+			By("Check ClusterRole")
+			clusterRole := &rbacv1.ClusterRole{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "view"}, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRole.Rules[0].Resources).To(Equal([]string{"configmaps"}))
+			Expect(clusterRole.OwnerReferences).To(BeEmpty())
+
+			By("Check status")
+			namespaceRole := &kobsiov1alpha2.NamespaceRole{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "view"}, namespaceRole)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRole.Status.ClusterRoles).To(BeEmpty())
+			Expect(namespaceRole.Status.Roles).To(HaveLen(1))
+			Expect(hasFailed("ClusterRole", "", "view", namespaceRole.Status.Failures)).To(BeTrue())
+		})
+
+		It("Should not split the rules, when the scope is not set", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithRESTMapper(newRESTMapper()).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRole{}).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup29"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Namespaces: []string{"default"},
+							Rules:      rules,
+						},
+					},
+				).
+				Build()
+
+			By("Reconciling NamespaceRole")
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup29"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check ClusterRole")
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup29"}, &rbacv1.ClusterRole{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("Check Role")
+			role := &rbacv1.Role{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup29", Namespace: "default"}, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role.Rules).To(Equal(rules))
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoles for ClusterRole", func() {
+	Context("When a ClusterRole is changed", func() {
+		ctx := context.Background()
+
+		It("Should return all NamespaceRoles including or selecting the ClusterRole", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithIndex(&kobsiov1alpha2.NamespaceRole{}, includesIndexKey, indexIncludes).
+				WithObjects(
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup30a"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Includes: []kobsiov1alpha2.NamespaceRoleInclude{{Kind: kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole, Name: "kobs-base"}},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup30b"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Includes: []kobsiov1alpha2.NamespaceRoleInclude{{Kind: kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole, Name: "kobs-mygroup30a"}},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup30c"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							RuleSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"kobs.io/aggregate-to-readonly": "true"},
+							},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup30d"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Includes: []kobsiov1alpha2.NamespaceRoleInclude{{Kind: kobsiov1alpha2.NamespaceRoleIncludeKindNamespaceRole, Name: "kobs-mygroup30c"}},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup30e"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Includes: []kobsiov1alpha2.NamespaceRoleInclude{{Kind: kobsiov1alpha2.NamespaceRoleIncludeKindClusterRole, Name: "view"}},
+							RuleSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"kobs.io/aggregate-to-admin": "true"},
+							},
+						},
+					},
+				).
+				Build()
+
+			controllerNamespaceRoleReconciler := &NamespaceRoleReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+
+			By("Map included and selected ClusterRole")
+			requests := controllerNamespaceRoleReconciler.findNamespaceRolesForClusterRole(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "kobs-base",
+					Labels: map[string]string{"kobs.io/aggregate-to-readonly": "true"},
+				},
+			})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30b"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30c"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30d"}},
+			))
+
+			By("Map ClusterRole created by the operator")
+			requests = controllerNamespaceRoleReconciler.findNamespaceRolesForClusterRole(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "kobs-base",
+					Labels: map[string]string{
+						selectorLabelKeyNR:              "kobs-base",
+						"kobs.io/aggregate-to-readonly": "true",
+					},
+				},
+			})
+			Expect(requests).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup30b"}},
+			))
+
+			By("Map unrelated ClusterRole")
+			requests = controllerNamespaceRoleReconciler.findNamespaceRolesForClusterRole(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "edit"},
+			})
+			Expect(requests).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("NamespaceRoleBinding with templated subjects for a ClusterRole", func() {
+	Context("When the NamespaceRoleBinding is not narrowed to namespaces", func() {
+		ctx := context.Background()
+
+		It("Should report that the subjects can not be bound", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup31"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+								Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+								Name: "view",
+							}},
+							Subjects: []rbacv1.Subject{{
+								Kind:      "ServiceAccount",
+								Name:      "ci",
+								Namespace: "${namespace}",
+							}},
+						},
+					},
+				).
+				Build()
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup31"}})
+			Expect(err).To(MatchError(ContainSubstring("subjects with variables")))
+
+			By("Check status")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup31"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.ClusterRoleBindings).To(BeEmpty())
+			Expect(hasFailed("NamespaceRoleBinding", "", "kobs-mygroup31", namespaceRoleBinding.Status.Failures)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(namespaceRoleBinding.Status.Conditions, kobsiov1alpha2.ConditionTypeReady)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Schedule window", func() {
+	mustParse := func(value string) time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("Should return the state of the schedule",
+		func(schedule kobsiov1alpha2.NamespaceRoleBindingSchedule, now, expectedNextTransition string, expectedActive bool) {
+			v, err := getScheduleWindow(schedule, mustParse(now))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.Active).To(Equal(expectedActive))
+			Expect(v.NextTransition).To(BeTemporally("==", mustParse(expectedNextTransition)))
+			if expectedActive {
+				Expect(v.Reason).To(Equal("InsideSchedule"))
+			} else {
+				Expect(v.Reason).To(Equal("OutsideSchedule"))
+			}
+		},
+		Entry("inside of a window",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}},
+			"2026-03-02T12:00:00Z", "2026-03-02T17:00:00Z", true,
+		),
+		Entry("outside of a window",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}},
+			"2026-03-02T18:00:00Z", "2026-03-03T09:00:00Z", false,
+		),
+		Entry("exactly at the start of a window",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}},
+			"2026-03-02T09:00:00Z", "2026-03-02T17:00:00Z", true,
+		),
+		Entry("exactly at the end of a window",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}},
+			"2026-03-02T17:00:00Z", "2026-03-03T09:00:00Z", false,
+		),
+		Entry("inside of overlapping windows",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9,12 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			"2026-03-02T10:00:00Z", "2026-03-02T16:00:00Z", true,
+		),
+		Entry("inside of a window in a non-UTC time zone",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}, TimeZone: "Europe/Berlin"},
+			"2026-03-02T12:00:00Z", "2026-03-02T16:00:00Z", true,
+		),
+		Entry("outside of a window before a DST change",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}, TimeZone: "Europe/Berlin"},
+			"2026-03-28T20:00:00Z", "2026-03-29T07:00:00Z", false,
+		),
+		Entry("inside of a window after a DST change",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 8 * time.Hour}, TimeZone: "Europe/Berlin"},
+			"2026-03-29T08:00:00Z", "2026-03-29T15:00:00Z", true,
+		),
+	)
+
+	DescribeTable("Should return an error for an invalid schedule",
+		func(schedule kobsiov1alpha2.NamespaceRoleBindingSchedule, expectedErr string) {
+			_, err := getScheduleWindow(schedule, mustParse("2026-03-02T12:00:00Z"))
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("invalid cron expression",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "every monday", Duration: metav1.Duration{Duration: time.Hour}},
+			"invalid cron expression",
+		),
+		Entry("invalid duration",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: 0}},
+			"invalid duration",
+		),
+		Entry("invalid time zone",
+			kobsiov1alpha2.NamespaceRoleBindingSchedule{Cron: "0 9 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Europe/Nowhere"},
+			"invalid time zone",
+		),
+	)
+})
+
+var _ = Describe("AccessRequest with a changed status", func() {
+	Context("When the status was changed by another client", func() {
+		ctx := context.Background()
+
+		newAccessRequest := func(name string, approvals []kobsiov1alpha2.AccessRequestApproval, status kobsiov1alpha2.AccessRequestStatus) *kobsiov1alpha2.AccessRequest {
+			return &kobsiov1alpha2.AccessRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Spec: kobsiov1alpha2.AccessRequestSpec{
+					RoleRef: kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{Name: "kobs-mygroup32"},
+					Subjects: []rbacv1.Subject{{
+						APIGroup: "rbac.authorization.k8s.io",
+						Kind:     "User",
+						Name:     "bob",
+					}},
+					Duration:    metav1.Duration{Duration: time.Hour},
+					RequestedBy: "bob",
+					Approvals:   approvals,
+				},
+				Status: status,
+			}
+		}
+
+		reconcileAccessRequest := func(fakeClient client.Client, name string) (*kobsiov1alpha2.AccessRequest, error) {
+			controllerAccessRequestReconciler := &AccessRequestReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, reconcileErr := controllerAccessRequestReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+
+			accessRequest := &kobsiov1alpha2.AccessRequest{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: name}, accessRequest)).To(Succeed())
+			return accessRequest, reconcileErr
+		}
+
+		It("Should compute the expiration from the approvals", func() {
+			approvedAt := metav1.NewTime(time.Now().Add(-10 * time.Minute).Truncate(time.Second))
+			expiredAt := metav1.NewTime(time.Now().Add(-2 * time.Hour).Truncate(time.Second))
+			forgedAt := metav1.NewTime(time.Now().Add(24 * time.Hour))
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.AccessRequest{}).
+				WithObjects(
+					newAccessRequest("kobs-mygroup32a", []kobsiov1alpha2.AccessRequestApproval{
+						{User: "alice", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: approvedAt},
+						{User: "dave", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+					}, kobsiov1alpha2.AccessRequestStatus{
+						Phase:      kobsiov1alpha2.AccessRequestPhaseApproved,
+						ApprovedAt: &forgedAt,
+						ExpiresAt:  &forgedAt,
+					}),
+					newAccessRequest("kobs-mygroup32b", []kobsiov1alpha2.AccessRequestApproval{
+						{User: "alice", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: expiredAt},
+					}, kobsiov1alpha2.AccessRequestStatus{
+						Phase:      kobsiov1alpha2.AccessRequestPhasePending,
+						ApprovedAt: &forgedAt,
+						ExpiresAt:  &forgedAt,
+					}),
+				).
+				Build()
+
+			By("Check approved AccessRequest")
+			accessRequest, err := reconcileAccessRequest(fakeClient, "kobs-mygroup32a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhaseApproved))
+			Expect(accessRequest.Status.ApprovedBy).To(Equal([]string{"alice", "dave"}))
+			Expect(accessRequest.Status.ApprovedAt.Time).To(BeTemporally("==", approvedAt.Time))
+			Expect(accessRequest.Status.ExpiresAt.Time).To(BeTemporally("==", approvedAt.Add(time.Hour)))
+
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup32a"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Spec.ValidUntil.Time).To(BeTemporally("==", approvedAt.Add(time.Hour)))
+
+			By("Check expired AccessRequest")
+			accessRequest, err = reconcileAccessRequest(fakeClient, "kobs-mygroup32b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhaseExpired))
+			Expect(accessRequest.Status.ExpiresAt.Time).To(BeTemporally("==", expiredAt.Add(time.Hour)))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup32b"}, &kobsiov1alpha2.NamespaceRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should not revive a denied AccessRequest", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.AccessRequest{}).
+				WithObjects(
+					newAccessRequest("kobs-mygroup32c", []kobsiov1alpha2.AccessRequestApproval{
+						{User: "dave", Decision: kobsiov1alpha2.AccessRequestDecisionDenied, Time: metav1.Now()},
+						{User: "alice", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+					}, kobsiov1alpha2.AccessRequestStatus{
+						Phase: kobsiov1alpha2.AccessRequestPhasePending,
+					}),
+				).
+				Build()
+
+			accessRequest, err := reconcileAccessRequest(fakeClient, "kobs-mygroup32c")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhaseDenied))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "accessrequest-kobs-mygroup32c"}, &kobsiov1alpha2.NamespaceRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should ignore approvals of the creator and approvals which were not admitted by the webhook", func() {
+			accessRequest := newAccessRequest("kobs-mygroup32d", []kobsiov1alpha2.AccessRequestApproval{
+				{User: "bob", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+				{User: "alice", Decision: kobsiov1alpha2.AccessRequestDecisionApproved},
+				{Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.Now()},
+			}, kobsiov1alpha2.AccessRequestStatus{})
+			accessRequest.Spec.Subjects[0].Name = "eve"
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.AccessRequest{}).
+				WithObjects(accessRequest, newAccessRequest("kobs-mygroup32e", nil, kobsiov1alpha2.AccessRequestStatus{})).
+				Build()
+
+			accessRequest, err := reconcileAccessRequest(fakeClient, "kobs-mygroup32d")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhasePending))
+			Expect(accessRequest.Status.ApprovedBy).To(BeEmpty())
+			Expect(accessRequest.Status.ApprovedAt).To(BeNil())
+
+			By("Check AccessRequest without creator")
+			accessRequest = &kobsiov1alpha2.AccessRequest{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup32e"}, accessRequest)).To(Succeed())
+			accessRequest.Spec.RequestedBy = ""
+			Expect(fakeClient.Update(ctx, accessRequest)).To(Succeed())
+
+			accessRequest, err = reconcileAccessRequest(fakeClient, "kobs-mygroup32e")
+			Expect(err).To(HaveOccurred())
+			Expect(accessRequest.Status.Phase).To(Equal(kobsiov1alpha2.AccessRequestPhasePending))
+			Expect(meta.IsStatusConditionTrue(accessRequest.Status.Conditions, kobsiov1alpha2.ConditionTypeDegraded)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Break-glass activation", func() {
+	ctx := context.Background()
+
+	newNamespaceRoleBinding := func(name string) *kobsiov1alpha2.NamespaceRoleBinding {
+		return &kobsiov1alpha2.NamespaceRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+				RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+					Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+					Name: "view",
+				}},
+				Subjects: []rbacv1.Subject{{
+					APIGroup: "rbac.authorization.k8s.io",
+					Kind:     "User",
+					Name:     "jane",
+				}},
+				BreakGlass: &kobsiov1alpha2.NamespaceRoleBindingBreakGlass{
+					Justification: "INC-1234",
+				},
+			},
+		}
+	}
+
+	Context("When the status can not be saved", func() {
+		It("Should report the activation only once and keep the activation time", func() {
+			failStatusUpdate := true
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(newNamespaceRoleBinding("kobs-mygroup33a")).
+				WithInterceptorFuncs(interceptor.Funcs{
+					SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+						if failStatusUpdate {
+							failStatusUpdate = false
+							return errors.NewConflict(schema.GroupResource{Group: "kobs.io", Resource: "namespacerolebindings"}, obj.GetName(), fmt.Errorf("conflict"))
+						}
+						return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+					},
+				}).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client:           fakeClient,
+				Scheme:           fakeClient.Scheme(),
+				Recorder:         recorder,
+				BreakGlassMaxTTL: 30 * time.Minute,
+			}
+
+			By("Reconciling NamespaceRoleBinding with a failing status update")
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup33a"}})
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.Events).To(Receive(ContainSubstring("INC-1234")))
+
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup33a"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			activatedAt := namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation]
+			Expect(activatedAt).NotTo(BeEmpty())
+
+			By("Reconciling NamespaceRoleBinding again")
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup33a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup33a"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation]).To(Equal(activatedAt))
+			Expect(namespaceRoleBinding.Status.BreakGlass.ActivatedAt.UTC().Format(time.RFC3339)).To(Equal(activatedAt))
+			Expect(namespaceRoleBinding.Status.BreakGlass.ExpiresAt.Time).To(BeTemporally("==", namespaceRoleBinding.Status.BreakGlass.ActivatedAt.Add(30*time.Minute)))
+
+			By("Reset the activation in the status")
+			namespaceRoleBinding.Status.BreakGlass = nil
+			Expect(fakeClient.Status().Update(ctx, namespaceRoleBinding)).To(Succeed())
+
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup33a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).NotTo(Receive())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup33a"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.BreakGlass.ActivatedAt.UTC().Format(time.RFC3339)).To(Equal(activatedAt))
+		})
+
+		It("Should not report the activation when it can not be saved", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(newNamespaceRoleBinding("kobs-mygroup33b")).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if _, ok := obj.(*kobsiov1alpha2.NamespaceRoleBinding); ok {
+							return fmt.Errorf("patch failed")
+						}
+						return c.Patch(ctx, obj, patch, opts...)
+					},
+				}).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup33b"}})
+			Expect(err).To(MatchError(ContainSubstring("patch failed")))
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("Check ClusterRoleBinding")
+			clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+			Expect(fakeClient.List(ctx, clusterRoleBindings)).To(Succeed())
+			Expect(clusterRoleBindings.Items).To(BeEmpty())
+		})
+	})
+
+	Context("When the activation annotation is forged", func() {
+		DescribeTable("Should record and report a new activation",
+			func(name, activatedAt string) {
+				namespaceRoleBinding := newNamespaceRoleBinding(name)
+				namespaceRoleBinding.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute).Truncate(time.Second))
+				namespaceRoleBinding.Annotations = map[string]string{breakGlassActivatedAnnotation: activatedAt}
+
+				fakeClient := fake.NewClientBuilder().
+					WithScheme(scheme.Scheme).
+					WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+					WithObjects(namespaceRoleBinding).
+					Build()
+
+				recorder := record.NewFakeRecorder(10)
+				controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+					Client:           fakeClient,
+					Scheme:           fakeClient.Scheme(),
+					Recorder:         recorder,
+					BreakGlassMaxTTL: 30 * time.Minute,
+				}
+
+				now := time.Now()
+				_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recorder.Events).To(Receive(ContainSubstring("INC-1234")))
+
+				err = fakeClient.Get(ctx, types.NamespacedName{Name: name}, namespaceRoleBinding)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(namespaceRoleBinding.Annotations[breakGlassActivatedAnnotation]).NotTo(Equal(activatedAt))
+				Expect(namespaceRoleBinding.Status.BreakGlass.ActivatedAt.Time).To(BeTemporally("~", now, 2*time.Second))
+				Expect(namespaceRoleBinding.Status.BreakGlass.ExpiresAt.Time).To(BeTemporally("~", now.Add(30*time.Minute), 2*time.Second))
+				Expect(namespaceRoleBinding.Status.NextTransitionTime.Time).To(BeTemporally("~", now.Add(30*time.Minute), 2*time.Second))
+			},
+			Entry("activation in the future", "kobs-mygroup33c", "2099-01-01T00:00:00Z"),
+			Entry("activation before the creation", "kobs-mygroup33d", "2000-01-01T00:00:00Z"),
+			Entry("invalid activation", "kobs-mygroup33e", "yesterday"),
+		)
+	})
+})
+
+var _ = Describe("Drift of generated bindings", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		newNamespaceRoleBinding := func(name string, namespaces []string) *kobsiov1alpha2.NamespaceRoleBinding {
+			return &kobsiov1alpha2.NamespaceRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+				Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+					RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+						Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+						Name: "view",
+					}},
+					Namespaces: namespaces,
+					Subjects: []rbacv1.Subject{{
+						APIGroup: "rbac.authorization.k8s.io",
+						Kind:     "Group",
+						Name:     "group:default/developers",
+					}},
+				},
+			}
+		}
+
+		It("Should restore changed and deleted ClusterRoleBindings and RoleBindings", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+					newNamespaceRoleBinding("kobs-mygroup34a", nil),
+					newNamespaceRoleBinding("kobs-mygroup34b", []string{"team-a"}),
+				).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeClient.Scheme(),
+				Recorder: recorder,
+			}
+			reconcileNamespaceRoleBinding := func(name string) {
+				_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("Reconciling NamespaceRoleBindings")
+			reconcileNamespaceRoleBinding("kobs-mygroup34a")
+			reconcileNamespaceRoleBinding("kobs-mygroup34b")
+			Expect(recorder.Events).NotTo(Receive())
+
+			By("Change ClusterRoleBinding")
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			err := fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34a-clusterrole-view"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			// The fake client doesn't set the creation timestamp, which is
+			// required to detect the drift of an existing object.
+			clusterRoleBinding.CreationTimestamp = metav1.Now()
+			clusterRoleBinding.Subjects = append(clusterRoleBinding.Subjects, rbacv1.Subject{APIGroup: "rbac.authorization.k8s.io", Kind: "User", Name: "mallory"})
+			Expect(fakeClient.Update(ctx, clusterRoleBinding)).To(Succeed())
+
+			reconcileNamespaceRoleBinding("kobs-mygroup34a")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Restored ClusterRoleBinding kobs-mygroup34a-clusterrole-view")))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34a-clusterrole-view"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleBinding.Subjects).To(HaveLen(1))
+
+			By("Delete ClusterRoleBinding")
+			Expect(fakeClient.Delete(ctx, clusterRoleBinding)).To(Succeed())
+
+			reconcileNamespaceRoleBinding("kobs-mygroup34a")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Restored ClusterRoleBinding kobs-mygroup34a-clusterrole-view")))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34a-clusterrole-view"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Change RoleBinding")
+			roleBinding := &rbacv1.RoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34b-clusterrole-view", Namespace: "team-a"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			roleBinding.CreationTimestamp = metav1.Now()
+			roleBinding.Subjects[0].Name = "system:authenticated"
+			Expect(fakeClient.Update(ctx, roleBinding)).To(Succeed())
+
+			reconcileNamespaceRoleBinding("kobs-mygroup34b")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Restored RoleBinding team-a/kobs-mygroup34b-clusterrole-view")))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34b-clusterrole-view", Namespace: "team-a"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.Subjects[0].Name).To(Equal("group:default/developers"))
+
+			By("Delete RoleBinding")
+			Expect(fakeClient.Delete(ctx, roleBinding)).To(Succeed())
+
+			reconcileNamespaceRoleBinding("kobs-mygroup34b")
+			Expect(recorder.Events).To(Receive(ContainSubstring("Restored RoleBinding team-a/kobs-mygroup34b-clusterrole-view")))
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup34b-clusterrole-view", Namespace: "team-a"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Reconciling unchanged NamespaceRoleBindings")
+			reconcileNamespaceRoleBinding("kobs-mygroup34a")
+			reconcileNamespaceRoleBinding("kobs-mygroup34b")
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
+
+	Context("When a generated object is changed", func() {
+		It("Should only accept objects with the label", func() {
+			labeled := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup34c", Labels: map[string]string{selectorLabelKeyNRB: "kobs-mygroup34c"}}}
+			unlabeled := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup34c"}}
+
+			p := hasLabelPredicate(selectorLabelKeyNRB)
+			Expect(p.Create(event.CreateEvent{Object: labeled})).To(BeTrue())
+			Expect(p.Create(event.CreateEvent{Object: unlabeled})).To(BeFalse())
+			Expect(p.Delete(event.DeleteEvent{Object: labeled})).To(BeTrue())
+			Expect(p.Delete(event.DeleteEvent{Object: unlabeled})).To(BeFalse())
+			Expect(p.Generic(event.GenericEvent{Object: unlabeled})).To(BeFalse())
+
+			By("Remove label")
+			Expect(p.Update(event.UpdateEvent{ObjectOld: labeled, ObjectNew: unlabeled})).To(BeTrue())
+
+			By("Add label")
+			Expect(p.Update(event.UpdateEvent{ObjectOld: unlabeled, ObjectNew: labeled})).To(BeTrue())
+
+			By("Update without label")
+			Expect(p.Update(event.UpdateEvent{ObjectOld: unlabeled, ObjectNew: unlabeled})).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Existing bindings with the name of a generated binding", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		It("Should not adopt bindings, which were not created by the operator", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+					&rbacv1.ClusterRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin", CreationTimestamp: metav1.Now()},
+						RoleRef: rbacv1.RoleRef{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "ClusterRole",
+							Name:     "cluster-admin",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "system:masters",
+						}},
+					},
+					&rbacv1.RoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup35-clusterrole-view", Namespace: "team-a", CreationTimestamp: metav1.Now()},
+						RoleRef: rbacv1.RoleRef{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "ClusterRole",
+							Name:     "view",
+						},
+						Subjects: []rbacv1.Subject{{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "Group",
+							Name:     "team-a",
+						}},
+					},
+					&kobsiov1alpha2.NamespaceRole{
+						ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+						Spec: kobsiov1alpha2.NamespaceRoleSpec{
+							Scope:          kobsiov1alpha2.NamespaceRoleScopeCluster,
+							ClusterRoleRef: "cluster-admin",
+						},
+						Status: kobsiov1alpha2.NamespaceRoleStatus{
+							ClusterRoleRef: "cluster-admin",
+						},
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin", UID: "cluster-admin"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRef: &kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{Name: "cluster-admin"},
+							Subjects: []rbacv1.Subject{{
+								APIGroup: "rbac.authorization.k8s.io",
+								Kind:     "User",
+								Name:     "mallory",
+							}},
+						},
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup35", UID: "kobs-mygroup35"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+								Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+								Name: "view",
+							}},
+							Namespaces: []string{"team-a"},
+							Subjects: []rbacv1.Subject{{
+								APIGroup: "rbac.authorization.k8s.io",
+								Kind:     "User",
+								Name:     "mallory",
+							}},
+						},
+					},
+				).
+				Build()
+
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+
+			By("Reconciling NamespaceRoleBinding for a ClusterRoleBinding")
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "cluster-admin"}})
+			Expect(err).To(MatchError(ContainSubstring("not managed by the NamespaceRoleBinding")))
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "cluster-admin"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleBinding.Subjects[0].Name).To(Equal("system:masters"))
+			Expect(clusterRoleBinding.OwnerReferences).To(BeEmpty())
+
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "cluster-admin"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.ClusterRoleBindings).To(BeEmpty())
+			Expect(hasFailed("ClusterRoleBinding", "", "cluster-admin", namespaceRoleBinding.Status.Failures)).To(BeTrue())
+
+			By("Reconciling NamespaceRoleBinding for a RoleBinding")
+			_, err = controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup35"}})
+			Expect(err).To(MatchError(ContainSubstring("not managed by the NamespaceRoleBinding")))
+
+			roleBinding := &rbacv1.RoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup35-clusterrole-view", Namespace: "team-a"}, roleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleBinding.Subjects[0].Name).To(Equal("team-a"))
+			Expect(roleBinding.OwnerReferences).To(BeEmpty())
+		})
+
+		It("Should adopt bindings, which were created by a previous version of the operator", func() {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(
+					&rbacv1.ClusterRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              "kobs-mygroup35a-clusterrole-view",
+							Labels:            map[string]string{selectorLabelKeyNRB: "kobs-mygroup35a"},
+							CreationTimestamp: metav1.Now(),
+						},
+						RoleRef: rbacv1.RoleRef{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "ClusterRole",
+							Name:     "view",
+						},
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup35a", UID: "kobs-mygroup35a"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+								Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+								Name: "view",
+							}},
+							Subjects: []rbacv1.Subject{{
+								APIGroup: "rbac.authorization.k8s.io",
+								Kind:     "User",
+								Name:     "jane",
+							}},
+						},
+					},
+				).
+				Build()
+
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+
+			By("Reconciling NamespaceRoleBinding")
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup35a"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check ClusterRoleBinding")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup35a"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup35a-clusterrole-view"}, clusterRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleBinding.Subjects).To(HaveLen(1))
+			Expect(clusterRoleBinding.Subjects[0].Name).To(Equal("jane"))
+			Expect(metav1.IsControlledBy(clusterRoleBinding, namespaceRoleBinding)).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Stale bindings from the status", func() {
+	Context("When reconciling a resource", func() {
+		ctx := context.Background()
+
+		It("Should only delete bindings, which were created by the operator", func() {
+			roleRef := rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     "view",
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme.Scheme).
+				WithStatusSubresource(&kobsiov1alpha2.NamespaceRoleBinding{}).
+				WithObjects(
+					&rbacv1.ClusterRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "system:basic-user"},
+						RoleRef:    roleRef,
+					},
+					&rbacv1.RoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "system:controller:bootstrap-signer", Namespace: "kube-system"},
+						RoleRef:    roleRef,
+					},
+					&rbacv1.ClusterRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   "kobs-mygroup36-namespacerole-kobs-mygroup36",
+							Labels: map[string]string{selectorLabelKeyNRB: "kobs-mygroup36"},
+						},
+						RoleRef: roleRef,
+					},
+					&kobsiov1alpha2.NamespaceRoleBinding{
+						ObjectMeta: metav1.ObjectMeta{Name: "kobs-mygroup36", UID: "kobs-mygroup36"},
+						Spec: kobsiov1alpha2.NamespaceRoleBindingSpec{
+							RoleRefs: []kobsiov1alpha2.NamespaceRoleBindingRoleRef{{
+								Kind: kobsiov1alpha2.NamespaceRoleBindingRoleRefKindClusterRole,
+								Name: "view",
+							}},
+							Subjects: []rbacv1.Subject{{
+								APIGroup: "rbac.authorization.k8s.io",
+								Kind:     "User",
+								Name:     "jane",
+							}},
+						},
+						Status: kobsiov1alpha2.NamespaceRoleBindingStatus{
+							ClusterRoleBindings: []kobsiov1alpha2.NamespaceRoleStatusRoleBinding{
+								{Name: "system:basic-user"},
+								{Name: "kobs-mygroup36-namespacerole-kobs-mygroup36"},
+							},
+							RoleBindings: []kobsiov1alpha2.NamespaceRoleStatusRoleBinding{
+								{Name: "system:controller:bootstrap-signer", Namespace: "kube-system"},
+							},
+						},
+					},
+				).
+				Build()
+
+			By("Reconciling NamespaceRoleBinding")
+			controllerNamespaceRoleBindingReconciler := &NamespaceRoleBindingReconciler{
+				Client: fakeClient,
+				Scheme: fakeClient.Scheme(),
+			}
+			_, err := controllerNamespaceRoleBindingReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "kobs-mygroup36"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check bindings")
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "system:basic-user"}, &rbacv1.ClusterRoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "system:controller:bootstrap-signer", Namespace: "kube-system"}, &rbacv1.RoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup36-namespacerole-kobs-mygroup36"}, &rbacv1.ClusterRoleBinding{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup36-clusterrole-view"}, &rbacv1.ClusterRoleBinding{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Check status")
+			namespaceRoleBinding := &kobsiov1alpha2.NamespaceRoleBinding{}
+			err = fakeClient.Get(ctx, types.NamespacedName{Name: "kobs-mygroup36"}, namespaceRoleBinding)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespaceRoleBinding.Status.ClusterRoleBindings).To(Equal([]kobsiov1alpha2.NamespaceRoleStatusRoleBinding{{Name: "kobs-mygroup36-clusterrole-view"}}))
+			Expect(namespaceRoleBinding.Status.RoleBindings).To(BeEmpty())
+		})
+	})
+})
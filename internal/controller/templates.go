@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// templateVariableRegexp matches the variables in the rules of a Role, e.g.
+// "${namespace}" or "${label:team}".
+var templateVariableRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandRules replaces the variables in the API groups, resources and resource
+// names of the provided rules with the values for the provided namespace. The
+// "${namespace}" variable is replaced with the name of the namespace and the
+// "${label:<key>}" variable is replaced with the value of the label with the
+// provided key. If a variable is unknown or a label doesn't exist on the
+// namespace an error is returned, so that we never grant access to an
+// unexpected resource.
+func expandRules(rules []rbacv1.PolicyRule, namespace *corev1.Namespace) ([]rbacv1.PolicyRule, error) {
+	var expandedRules []rbacv1.PolicyRule
+
+	for _, rule := range rules {
+		expandedRule := *rule.DeepCopy()
+
+		for _, values := range [][]string{expandedRule.APIGroups, expandedRule.Resources, expandedRule.ResourceNames} {
+			for i, value := range values {
+				expandedValue, err := expandTemplate(value, namespace)
+				if err != nil {
+					return nil, err
+				}
+
+				values[i] = expandedValue
+			}
+		}
+
+		expandedRules = append(expandedRules, expandedRule)
+	}
+
+	return expandedRules, nil
+}
+
+// expandTemplate replaces all variables in the provided value with the values
+// for the provided namespace.
+func expandTemplate(value string, namespace *corev1.Namespace) (string, error) {
+	var err error
+
+	expandedValue := templateVariableRegexp.ReplaceAllStringFunc(value, func(match string) string {
+		variable := templateVariableRegexp.FindStringSubmatch(match)[1]
+
+		if variable == "namespace" {
+			return namespace.Name
+		}
+
+		if key, ok := strings.CutPrefix(variable, "label:"); ok {
+			if labelValue, ok := namespace.Labels[key]; ok {
+				return labelValue
+			}
+
+			if err == nil {
+				err = fmt.Errorf("label %q not found on namespace %q", key, namespace.Name)
+			}
+			return match
+		}
+
+		if err == nil {
+			err = fmt.Errorf("unknown variable %q", match)
+		}
+		return match
+	})
+
+	return expandedValue, err
+}
+
+// expandSubjects replaces the variables in the names and namespaces of the
+// provided subjects with the values for the provided namespace, so that e.g.
+// the ServiceAccount of each namespace can be bound to the Role in the same
+// namespace.
+func expandSubjects(subjects []rbacv1.Subject, namespace *corev1.Namespace) ([]rbacv1.Subject, error) {
+	var expandedSubjects []rbacv1.Subject
+
+	for _, subject := range subjects {
+		name, err := expandTemplate(subject.Name, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		subjectNamespace, err := expandTemplate(subject.Namespace, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		subject.Name = name
+		subject.Namespace = subjectNamespace
+		expandedSubjects = append(expandedSubjects, subject)
+	}
+
+	return expandedSubjects, nil
+}
+
+// hasTemplate returns true when the provided value contains a variable.
+func hasTemplate(value string) bool {
+	return templateVariableRegexp.MatchString(value)
+}
+
+// withoutTemplatedSubjects returns all subjects, which do not contain a
+// variable. Variables can only be expanded for RoleBindings, so that subjects
+// with variables are skipped for ClusterRoleBindings.
+func withoutTemplatedSubjects(subjects []rbacv1.Subject) []rbacv1.Subject {
+	var filteredSubjects []rbacv1.Subject
+
+	for _, subject := range subjects {
+		if !hasTemplate(subject.Name) && !hasTemplate(subject.Namespace) {
+			filteredSubjects = append(filteredSubjects, subject)
+		}
+	}
+
+	return filteredSubjects
+}
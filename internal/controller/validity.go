@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// maxScheduleWindows is the maximum number of overlapping windows of a
+	// schedule, which are merged to find the end of the current window.
+	maxScheduleWindows = 1000
+)
+
+// validity is the state of the validity window of a NamespaceRoleBinding at a
+// specific time.
+type validity struct {
+	// Active is true when the roles should be bound.
+	Active bool
+	// NextTransition is the time when the NamespaceRoleBinding becomes active
+	// or inactive. It is zero when there is no further transition.
+	NextTransition time.Time
+	// Reason and Message are used for the Active condition.
+	Reason  string
+	Message string
+}
+
+// getValidity returns the state of the validity window and the schedule of the
+// provided NamespaceRoleBinding at the provided time.
+func getValidity(spec kobsiov1alpha2.NamespaceRoleBindingSpec, now time.Time) (validity, error) {
+	v := getValidityWindow(spec, now)
+	if !v.Active || spec.Schedule == nil {
+		return v, nil
+	}
+
+	s, err := getScheduleWindow(*spec.Schedule, now)
+	if err != nil {
+		return validity{}, err
+	}
+
+	// The NamespaceRoleBinding is active when it is inside of the validity
+	// window and inside of a window of the schedule. The next transition is
+	// the earliest transition of both.
+	if !v.NextTransition.IsZero() && (s.NextTransition.IsZero() || v.NextTransition.Before(s.NextTransition)) {
+		s.NextTransition = v.NextTransition
+		if s.Active {
+			s.Message = v.Message
+		} else {
+			s.Message = fmt.Sprintf("The access expires at %s before the next window of the schedule", v.NextTransition.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return s, nil
+}
+
+// getValidityWindow returns the state of the window defined by the ValidFrom
+// and ValidUntil fields at the provided time.
+func getValidityWindow(spec kobsiov1alpha2.NamespaceRoleBindingSpec, now time.Time) validity {
+	if spec.ValidFrom != nil && now.Before(spec.ValidFrom.Time) {
+		return validity{
+			Active:         false,
+			NextTransition: spec.ValidFrom.Time,
+			Reason:         "NotYetValid",
+			Message:        fmt.Sprintf("The access is granted at %s", spec.ValidFrom.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	if spec.ValidUntil != nil {
+		if !now.Before(spec.ValidUntil.Time) {
+			return validity{
+				Active:  false,
+				Reason:  "Expired",
+				Message: fmt.Sprintf("The access was revoked at %s", spec.ValidUntil.UTC().Format(time.RFC3339)),
+			}
+		}
+
+		return validity{
+			Active:         true,
+			NextTransition: spec.ValidUntil.Time,
+			Reason:         "Valid",
+			Message:        fmt.Sprintf("The access is revoked at %s", spec.ValidUntil.UTC().Format(time.RFC3339)),
+		}
+	}
+
+	return validity{
+		Active:  true,
+		Reason:  "Valid",
+		Message: "The access is not time-bounded",
+	}
+}
+
+// getScheduleWindow returns the state of the provided schedule at the provided
+// time. The current window is the first window, which starts after the
+// provided time minus the duration of a window. Overlapping windows are merged,
+// so that the access isn't revoked and granted again.
+func getScheduleWindow(schedule kobsiov1alpha2.NamespaceRoleBindingSchedule, now time.Time) (validity, error) {
+	location := time.UTC
+	if schedule.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(schedule.TimeZone)
+		if err != nil {
+			return validity{}, fmt.Errorf("invalid time zone %q: %w", schedule.TimeZone, err)
+		}
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return validity{}, fmt.Errorf("invalid cron expression %q: %w", schedule.Cron, err)
+	}
+
+	if schedule.Duration.Duration <= 0 {
+		return validity{}, fmt.Errorf("invalid duration %q: must be positive", schedule.Duration.Duration)
+	}
+
+	now = now.In(location)
+	start := cronSchedule.Next(now.Add(-schedule.Duration.Duration))
+
+	if start.IsZero() || start.After(now) {
+		return validity{
+			Active:         false,
+			NextTransition: start,
+			Reason:         "OutsideSchedule",
+			Message:        fmt.Sprintf("The access is granted at %s", start.UTC().Format(time.RFC3339)),
+		}, nil
+	}
+
+	end := start.Add(schedule.Duration.Duration)
+	for i := 0; i < maxScheduleWindows; i++ {
+		next := cronSchedule.Next(start)
+		if next.IsZero() || next.After(end) {
+			break
+		}
+
+		start = next
+		end = next.Add(schedule.Duration.Duration)
+	}
+
+	return validity{
+		Active:         true,
+		NextTransition: end,
+		Reason:         "InsideSchedule",
+		Message:        fmt.Sprintf("The access is revoked at %s", end.UTC().Format(time.RFC3339)),
+	}, nil
+}
+
+// setActiveCondition sets the Active condition based on the provided validity.
+func setActiveCondition(conditions *[]metav1.Condition, generation int64, v validity) {
+	status := metav1.ConditionFalse
+	if v.Active {
+		status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               kobsiov1alpha2.ConditionTypeActive,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             v.Reason,
+		Message:            v.Message,
+	})
+}
@@ -0,0 +1,247 @@
+package v1alpha2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// accessRequestApproveVerb is the verb an approver must be allowed to use
+	// for an AccessRequest, so that the decision of the approver is accepted.
+	accessRequestApproveVerb = "approve"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var accessrequestlog = logf.Log.WithName("accessrequest-resource")
+
+// SetupAccessRequestWebhookWithManager registers the webhook for AccessRequest
+// in the manager.
+func SetupAccessRequestWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&kobsiov1alpha2.AccessRequest{}).
+		WithValidator(&AccessRequestCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&AccessRequestCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-kobs-io-v1alpha2-accessrequest,mutating=true,failurePolicy=fail,sideEffects=None,groups=kobs.io,resources=accessrequests,verbs=create;update,versions=v1alpha2,name=maccessrequest-v1alpha2.kobs.io,admissionReviewVersions=v1
+
+// AccessRequestCustomDefaulter sets the creator of an AccessRequest and the
+// user and time of new approvals from the authenticated request, so that they
+// can not be forged by the client.
+type AccessRequestCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &AccessRequestCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter. On create the RequestedBy field is
+// set to the authenticated user. On create and update the User and Time fields
+// of all approvals, which were not part of the old object, are set to the
+// authenticated user and the current time. Client supplied values, which do not
+// match the authenticated user, are rejected by the validating webhook.
+func (d *AccessRequestCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	accessRequest, ok := obj.(*kobsiov1alpha2.AccessRequest)
+	if !ok {
+		return fmt.Errorf("expected an AccessRequest object but got %T", obj)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	accessrequestlog.Info("Defaulting for AccessRequest", "name", accessRequest.GetName(), "user", req.UserInfo.Username)
+
+	existingApprovals := 0
+	switch req.Operation {
+	case admissionv1.Create:
+		if accessRequest.Spec.RequestedBy == "" {
+			accessRequest.Spec.RequestedBy = req.UserInfo.Username
+		}
+	case admissionv1.Update:
+		oldAccessRequest := &kobsiov1alpha2.AccessRequest{}
+		if err := json.Unmarshal(req.OldObject.Raw, oldAccessRequest); err != nil {
+			return err
+		}
+		existingApprovals = len(oldAccessRequest.Spec.Approvals)
+	}
+
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	for i := existingApprovals; i < len(accessRequest.Spec.Approvals); i++ {
+		if accessRequest.Spec.Approvals[i].User == "" {
+			accessRequest.Spec.Approvals[i].User = req.UserInfo.Username
+		}
+		accessRequest.Spec.Approvals[i].Time = now
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-kobs-io-v1alpha2-accessrequest,mutating=false,failurePolicy=fail,sideEffects=None,groups=kobs.io,resources=accessrequests,verbs=create;update,versions=v1alpha2,name=vaccessrequest-v1alpha2.kobs.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// AccessRequestCustomValidator validates the creator and the approvals of an
+// AccessRequest against the authenticated request.
+type AccessRequestCustomValidator struct {
+	client.Client
+}
+
+var _ webhook.CustomValidator = &AccessRequestCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator. It ensures that the
+// RequestedBy field is the authenticated user and validates all approvals.
+func (v *AccessRequestCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	accessRequest, ok := obj.(*kobsiov1alpha2.AccessRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected an AccessRequest object but got %T", obj)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessrequestlog.Info("Validation for AccessRequest upon creation", "name", accessRequest.GetName(), "user", req.UserInfo.Username)
+
+	if accessRequest.Spec.RequestedBy != req.UserInfo.Username {
+		return nil, fmt.Errorf("requestedBy must be the authenticated user %q", req.UserInfo.Username)
+	}
+
+	return nil, v.validateApprovals(ctx, accessRequest, accessRequest.Spec.Approvals, req.UserInfo)
+}
+
+// ValidateUpdate implements webhook.CustomValidator. It ensures that only new
+// approvals are added to the spec and validates them.
+func (v *AccessRequestCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldAccessRequest, ok := oldObj.(*kobsiov1alpha2.AccessRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected an AccessRequest object for the oldObj but got %T", oldObj)
+	}
+	accessRequest, ok := newObj.(*kobsiov1alpha2.AccessRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected an AccessRequest object for the newObj but got %T", newObj)
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessrequestlog.Info("Validation for AccessRequest upon update", "name", accessRequest.GetName(), "user", req.UserInfo.Username)
+
+	oldSpec := oldAccessRequest.Spec.DeepCopy()
+	newSpec := accessRequest.Spec.DeepCopy()
+	oldSpec.Approvals = nil
+	newSpec.Approvals = nil
+	if !equality.Semantic.DeepEqual(oldSpec, newSpec) {
+		return nil, fmt.Errorf("spec is immutable, only approvals can be added")
+	}
+
+	existingApprovals := len(oldAccessRequest.Spec.Approvals)
+	if len(accessRequest.Spec.Approvals) < existingApprovals || !equality.Semantic.DeepEqual(oldAccessRequest.Spec.Approvals, accessRequest.Spec.Approvals[:existingApprovals]) {
+		return nil, fmt.Errorf("approvals can not be changed or removed, only new approvals can be added")
+	}
+
+	return nil, v.validateApprovals(ctx, accessRequest, accessRequest.Spec.Approvals[existingApprovals:], req.UserInfo)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (v *AccessRequestCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateApprovals validates the provided new approvals of an AccessRequest.
+// A new approval must be made by the authenticated user, who must be allowed to
+// "approve" the AccessRequest according to a SubjectAccessReview and who must
+// not be the creator, a subject or a member of a group of the subjects of the
+// AccessRequest, so that nobody can approve their own request.
+func (v *AccessRequestCustomValidator) validateApprovals(ctx context.Context, accessRequest *kobsiov1alpha2.AccessRequest, approvals []kobsiov1alpha2.AccessRequestApproval, userInfo authenticationv1.UserInfo) error {
+	if len(approvals) == 0 {
+		return nil
+	}
+
+	for _, approval := range approvals {
+		if approval.User != userInfo.Username {
+			return fmt.Errorf("the user of an approval must be the authenticated user %q", userInfo.Username)
+		}
+		if approval.Time.IsZero() {
+			return fmt.Errorf("the time of an approval must be set")
+		}
+	}
+
+	if userInfo.Username == accessRequest.Spec.RequestedBy {
+		return fmt.Errorf("the creator of the AccessRequest can not approve or deny it")
+	}
+
+	for _, subject := range accessRequest.Spec.Subjects {
+		switch subject.Kind {
+		case "User":
+			if subject.Name == userInfo.Username {
+				return fmt.Errorf("a subject of the AccessRequest can not approve or deny it")
+			}
+		case "ServiceAccount":
+			if fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name) == userInfo.Username {
+				return fmt.Errorf("a subject of the AccessRequest can not approve or deny it")
+			}
+		case "Group":
+			if slices.Contains(userInfo.Groups, subject.Name) {
+				return fmt.Errorf("a member of the group %q of the AccessRequest can not approve or deny it", subject.Name)
+			}
+		}
+	}
+
+	subjectAccessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  convertExtra(userInfo.Extra),
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:    kobsiov1alpha2.GroupVersion.Group,
+				Resource: "accessrequests",
+				Verb:     accessRequestApproveVerb,
+				Name:     accessRequest.Name,
+			},
+		},
+	}
+
+	if err := v.Create(ctx, subjectAccessReview); err != nil {
+		return fmt.Errorf("failed to check approval of user %q: %w", userInfo.Username, err)
+	}
+	if !subjectAccessReview.Status.Allowed {
+		return fmt.Errorf("user %q is not allowed to approve the AccessRequest", userInfo.Username)
+	}
+
+	return nil
+}
+
+// convertExtra converts the extra information of an authenticated user to the
+// format of a SubjectAccessReview.
+func convertExtra(extra map[string]authenticationv1.ExtraValue) map[string]authorizationv1.ExtraValue {
+	if extra == nil {
+		return nil
+	}
+
+	converted := make(map[string]authorizationv1.ExtraValue, len(extra))
+	for key, value := range extra {
+		converted[key] = authorizationv1.ExtraValue(value)
+	}
+
+	return converted
+}
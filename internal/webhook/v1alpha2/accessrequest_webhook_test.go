@@ -0,0 +1,248 @@
+package v1alpha2
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	kobsiov1alpha2 "github.com/kobsio/namespacerole-operator/api/v1alpha2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("AccessRequest Webhook", func() {
+	var (
+		ctx       context.Context
+		validator AccessRequestCustomValidator
+		defaulter AccessRequestCustomDefaulter
+		oldObj    *kobsiov1alpha2.AccessRequest
+		obj       *kobsiov1alpha2.AccessRequest
+	)
+
+	// newContext returns a context with an admission request of the provided
+	// user, like it is passed to the webhook by the API server.
+	newContext := func(operation admissionv1.Operation, user string, groups ...string) context.Context {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				UserInfo: authenticationv1.UserInfo{
+					Username: user,
+					Groups:   groups,
+				},
+			},
+		}
+
+		if oldObj != nil {
+			raw, err := json.Marshal(oldObj)
+			Expect(err).NotTo(HaveOccurred())
+			req.OldObject = runtime.RawExtension{Raw: raw}
+		}
+
+		return admission.NewContextWithRequest(context.Background(), req)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		// The SubjectAccessReview only allows the approvers group to approve
+		// AccessRequests.
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if subjectAccessReview, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+						subjectAccessReview.Status.Allowed = subjectAccessReview.Spec.ResourceAttributes.Verb == accessRequestApproveVerb && slices.Contains(subjectAccessReview.Spec.Groups, "approvers")
+						return nil
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+			}).
+			Build()
+
+		validator = AccessRequestCustomValidator{Client: fakeClient}
+		defaulter = AccessRequestCustomDefaulter{}
+
+		oldObj = &kobsiov1alpha2.AccessRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: "production-debugging"},
+			Spec: kobsiov1alpha2.AccessRequestSpec{
+				RoleRef: kobsiov1alpha2.NamespaceRoleBindingSpecRoleRef{Name: "production-write"},
+				Subjects: []rbacv1.Subject{
+					{APIGroup: "rbac.authorization.k8s.io", Kind: "User", Name: "jane"},
+					{APIGroup: "rbac.authorization.k8s.io", Kind: "Group", Name: "developers"},
+				},
+				Duration:    metav1.Duration{Duration: time.Hour},
+				RequestedBy: "john",
+			},
+		}
+		obj = oldObj.DeepCopy()
+	})
+
+	Context("When creating an AccessRequest", func() {
+		BeforeEach(func() {
+			oldObj = nil
+			obj.Spec.RequestedBy = ""
+		})
+
+		It("Should set the creator from the authenticated user", func() {
+			ctx = newContext(admissionv1.Create, "john")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.RequestedBy).To(Equal("john"))
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a creator, which is not the authenticated user", func() {
+			obj.Spec.RequestedBy = "alice"
+
+			ctx = newContext(admissionv1.Create, "john")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("requestedBy must be the authenticated user")))
+		})
+
+		It("Should deny an approval by the creator", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Create, "john", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("the creator of the AccessRequest can not approve or deny it")))
+		})
+	})
+
+	Context("When adding an approval", func() {
+		It("Should set the user and time from the authenticated request", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Comment: "LGTM"}}
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Approvals[0].User).To(Equal("alice"))
+			Expect(obj.Spec.Approvals[0].Time.IsZero()).To(BeFalse())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should overwrite a client supplied time", func() {
+			forged := metav1.NewTime(time.Now().Add(24 * time.Hour))
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: forged}}
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Approvals[0].Time.Time).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("Should deny an approval for another user", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{User: "bob", Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("the user of an approval must be the authenticated user")))
+		})
+
+		It("Should deny an approval by the creator", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Update, "john", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("the creator of the AccessRequest can not approve or deny it")))
+		})
+
+		It("Should deny an approval by a subject", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Update, "jane", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("a subject of the AccessRequest can not approve or deny it")))
+		})
+
+		It("Should deny an approval by a member of a requested group", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers", "developers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring(`a member of the group "developers" of the AccessRequest can not approve or deny it`)))
+		})
+
+		It("Should deny an approval by a user, who is not allowed to approve", func() {
+			obj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{Decision: kobsiov1alpha2.AccessRequestDecisionApproved}}
+
+			ctx = newContext(admissionv1.Update, "mallory")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring(`user "mallory" is not allowed to approve the AccessRequest`)))
+		})
+	})
+
+	Context("When updating an AccessRequest", func() {
+		BeforeEach(func() {
+			oldObj.Spec.Approvals = []kobsiov1alpha2.AccessRequestApproval{{User: "alice", Decision: kobsiov1alpha2.AccessRequestDecisionApproved, Time: metav1.NewTime(time.Now().Truncate(time.Second))}}
+			obj = oldObj.DeepCopy()
+		})
+
+		It("Should allow updates without new approvals", func() {
+			obj.Labels = map[string]string{"team": "payments"}
+
+			ctx = newContext(admissionv1.Update, "john")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny changes of existing approvals", func() {
+			obj.Spec.Approvals[0].Decision = kobsiov1alpha2.AccessRequestDecisionDenied
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("approvals can not be changed or removed")))
+		})
+
+		It("Should deny the removal of approvals", func() {
+			obj.Spec.Approvals = nil
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("approvals can not be changed or removed")))
+		})
+
+		It("Should deny changes of the spec", func() {
+			obj.Spec.RequestedBy = "alice"
+
+			ctx = newContext(admissionv1.Update, "alice", "approvers")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec is immutable")))
+		})
+	})
+})